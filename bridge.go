@@ -0,0 +1,141 @@
+package commander
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewBridge constructs a new bridge that consumes messages from the consume topics of the source group
+// and republishes them to the produce topics of the target group with the same message type.
+// The dialects of both groups are expected to be opened (ex: by constructing a client) before a bridge is constructed.
+// A ErrNoTopic error is returned if the source group has no consume topics or if the target group has no
+// produce topics for one of the consumed message types.
+func NewBridge(source *Group, target *Group, definitions ...options.BridgeOption) (*Bridge, error) {
+	options := options.NewBridgeOptions(definitions)
+
+	bridge := &Bridge{
+		Source:        source,
+		Target:        target,
+		filter:        options.Filter,
+		actions:       options.Actions,
+		subscriptions: make(map[<-chan *types.Message]types.Dialect),
+		logger:        source.logger,
+	}
+
+	sorts := []types.MessageType{}
+	for _, sort := range []types.MessageType{EventMessage, CommandMessage} {
+		if len(source.FetchTopics(sort, ConsumeMode)) == 0 {
+			continue
+		}
+
+		if len(target.FetchTopics(sort, ProduceMode)) == 0 {
+			return nil, ErrNoTopic
+		}
+
+		sorts = append(sorts, sort)
+	}
+
+	if len(sorts) == 0 {
+		return nil, ErrNoTopic
+	}
+
+	for _, sort := range sorts {
+		dialects := groupTopicsByDialect(source.FetchTopics(sort, ConsumeMode))
+
+		for dialect, topics := range dialects {
+			messages, err := dialect.Consumer().Subscribe(topics...)
+			if err != nil {
+				bridge.Close()
+				return nil, err
+			}
+
+			bridge.subscriptions[messages] = dialect
+
+			bridge.consuming.Add(1)
+			go bridge.Forward(messages)
+		}
+	}
+
+	return bridge, nil
+}
+
+// Bridge republishes messages consumed from the source group to the target group.
+// Message ids, keys, headers and parent metadata are preserved. A consumed message is only
+// acknowledged once it has been published to the target topics, if publishing fails is the
+// consumed message negative acknowledged.
+type Bridge struct {
+	Source *Group
+	Target *Group
+
+	filter        func(*types.Message) bool
+	actions       map[string]string
+	subscriptions map[<-chan *types.Message]types.Dialect
+	consuming     sync.WaitGroup
+	logger        *log.Logger
+	mutex         sync.Mutex
+}
+
+// Forward republishes all messages consumed from the given subscription till the subscription is closed
+func (bridge *Bridge) Forward(messages <-chan *types.Message) {
+	defer bridge.consuming.Done()
+
+	for message := range messages {
+		if bridge.filter != nil && !bridge.filter(message) {
+			message.Ack()
+			continue
+		}
+
+		err := bridge.Publish(message)
+		if err != nil {
+			bridge.logger.Error(err)
+			message.Nack()
+			continue
+		}
+
+		message.Ack()
+	}
+}
+
+// Publish publishes a copy of the given message to the target topics of the same message type.
+// The message is published according to the produce policy of the target group. A best effort policy
+// is treated as ProduceAll to make sure that the consumed message is redelivered if publishing to one of the topics failed,
+// topics that have already received the message could receive it again once the message is redelivered.
+func (bridge *Bridge) Publish(message *types.Message) error {
+	policy := bridge.Target.Policy
+	if policy == ProduceBestEffort {
+		policy = ProduceAll
+	}
+
+	return bridge.Target.produce(message.Topic.Type(), bridge.copy(message), policy)
+}
+
+// copy constructs a copy of the given message with a renamed action if a rename is defined
+func (bridge *Bridge) copy(message *types.Message) *types.Message {
+	bridged := message.Copy()
+	if renamed, has := bridge.actions[bridged.Action]; has {
+		bridged.Action = renamed
+	}
+
+	return bridged
+}
+
+// Close unsubscribes all source subscriptions and awaits till all consumed messages are resolved
+func (bridge *Bridge) Close() error {
+	bridge.mutex.Lock()
+	defer bridge.mutex.Unlock()
+
+	for messages, dialect := range bridge.subscriptions {
+		err := dialect.Consumer().Unsubscribe(messages)
+		if err != nil {
+			return err
+		}
+
+		delete(bridge.subscriptions, messages)
+	}
+
+	bridge.consuming.Wait()
+	return nil
+}
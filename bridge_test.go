@@ -0,0 +1,212 @@
+package commander
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestBridge constructs a new source and target group backed by seperate mock dialects
+// and a bridge between the two groups. The bridge is closed once the test is completed.
+func NewTestBridge(t *testing.T, target *mock.Dialect, definitions ...options.BridgeOption) (*mock.Dialect, *Group) {
+	source := mock.NewDialect()
+	sourceGroup := NewGroup(
+		NewTopic("events", source, EventMessage, DefaultMode),
+	)
+
+	targetGroup := NewGroup(
+		NewTopic("mirror", target, EventMessage, DefaultMode),
+	)
+
+	targetGroup.Retries = 0
+
+	bridge, err := NewBridge(sourceGroup, targetGroup, definitions...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		bridge.Close()
+	})
+
+	return source, targetGroup
+}
+
+// TestBridgeForwarding tests if bridged messages preserve their id, key, headers and parent metadata
+func TestBridgeForwarding(t *testing.T) {
+	target := mock.NewDialect()
+	source, group := NewTestBridge(t, target)
+
+	messages, closing, err := group.NewConsumer(EventMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer closing()
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = types.NewTopic("events", source, EventMessage, DefaultMode)
+	message.Status = StatusConflict
+	message.EOS = true
+	message.NewCtx(metadata.AppendToHeaderContext(message.Ctx(), metadata.Header{"tenant": {"commander"}}))
+
+	source.Consumer().(*mock.Consumer).Emit(message)
+
+	err = message.Finally()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case bridged := <-messages:
+		bridged.Ack()
+
+		if bridged == message {
+			t.Fatal("message not copied")
+		}
+
+		if bridged.ID != message.ID || bridged.Action != "event" || bridged.Version != 2 {
+			t.Fatal("message id, action or version not preserved")
+		}
+
+		if string(bridged.Key) != "key" || string(bridged.Data) != "data" {
+			t.Fatal("message key or data not preserved")
+		}
+
+		if bridged.Status != StatusConflict || !bridged.EOS {
+			t.Fatal("message status or eos not preserved")
+		}
+
+		if bridged.Topic.Name() != "mirror" {
+			t.Fatalf("unexpected topic: %s", bridged.Topic.Name())
+		}
+
+		id, has := metadata.ParentIDFromContext(bridged.Ctx())
+		if !has || string(id) != parent.ID {
+			t.Fatal("parent id not preserved")
+		}
+
+		header, _ := metadata.HeaderFromContext(bridged.Ctx())
+		if header["tenant"].String() != "commander" {
+			t.Fatal("headers not preserved")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("bridged message not received")
+	}
+}
+
+// TestBridgeFilter tests if filtered messages are acknowledged without being republished
+func TestBridgeFilter(t *testing.T) {
+	recorder := mock.NewRecorder()
+	target := mock.NewDialect(mock.WithRecorder(recorder))
+	source, _ := NewTestBridge(t, target, WithBridgeFilter(func(message *types.Message) bool {
+		return message.Action != "ignored"
+	}))
+
+	topic := types.NewTopic("events", source, EventMessage, DefaultMode)
+
+	ignored := types.NewMessage("ignored", 1, nil, nil)
+	ignored.Topic = topic
+	source.Consumer().(*mock.Consumer).Emit(ignored)
+
+	if ignored.Finally() != nil {
+		t.Fatal("filtered message not acknowledged")
+	}
+
+	forwarded := types.NewMessage("forwarded", 1, nil, nil)
+	forwarded.Topic = topic
+	source.Consumer().(*mock.Consumer).Emit(forwarded)
+
+	if len(recorder.Messages()) != 1 {
+		t.Fatalf("unexpected amount of bridged messages: %d", len(recorder.Messages()))
+	}
+
+	if recorder.Messages()[0].ID != forwarded.ID {
+		t.Fatal("unexpected bridged message")
+	}
+}
+
+// TestBridgeActionRename tests if the action of bridged messages could be renamed
+func TestBridgeActionRename(t *testing.T) {
+	recorder := mock.NewRecorder()
+	target := mock.NewDialect(mock.WithRecorder(recorder))
+	source, _ := NewTestBridge(t, target, WithActionRename("created", "mirrored"))
+
+	message := types.NewMessage("created", 1, nil, nil)
+	message.Topic = types.NewTopic("events", source, EventMessage, DefaultMode)
+	source.Consumer().(*mock.Consumer).Emit(message)
+
+	if len(recorder.Messages()) != 1 {
+		t.Fatal("message not bridged")
+	}
+
+	if recorder.Messages()[0].Action != "mirrored" {
+		t.Fatalf("unexpected action: %s", recorder.Messages()[0].Action)
+	}
+
+	if message.Action != "created" {
+		t.Fatal("source message modified")
+	}
+}
+
+// TestBridgePublishFailure tests if consumed messages are negative acknowledged when the downstream publish fails
+func TestBridgePublishFailure(t *testing.T) {
+	target := mock.NewDialect(mock.WithFaults(mock.Faults{PublishErrorRate: 1}))
+	source, _ := NewTestBridge(t, target)
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = types.NewTopic("events", source, EventMessage, DefaultMode)
+	source.Consumer().(*mock.Consumer).Emit(message)
+
+	if message.Finally() != types.ErrNegativeAcknowledgement {
+		t.Fatal("message acknowledged while the downstream publish failed")
+	}
+}
+
+// TestBridgeBestEffortPartialFailure tests if consumed messages are negative acknowledged when publishing to one of the
+// target topics fails while the target group produces with a best effort policy
+func TestBridgeBestEffortPartialFailure(t *testing.T) {
+	source := mock.NewDialect()
+	sourceGroup := NewGroup(
+		NewTopic("events", source, EventMessage, DefaultMode),
+	)
+
+	targetGroup := NewGroup(
+		NewTopic("mirror", mock.NewDialect(), EventMessage, ProduceMode),
+		NewTopic("failing", mock.NewDialect(mock.WithFaults(mock.Faults{PublishErrorRate: 1})), EventMessage, ProduceMode),
+	)
+
+	targetGroup.Retries = 0
+	targetGroup.Policy = ProduceBestEffort
+
+	bridge, err := NewBridge(sourceGroup, targetGroup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer bridge.Close()
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = types.NewTopic("events", source, EventMessage, DefaultMode)
+	source.Consumer().(*mock.Consumer).Emit(message)
+
+	if message.Finally() != types.ErrNegativeAcknowledgement {
+		t.Fatal("message acknowledged while publishing to one of the target topics failed")
+	}
+}
+
+// TestBridgeNoTargetTopic tests if a error is returned when the target group has no matching produce topics
+func TestBridgeNoTargetTopic(t *testing.T) {
+	source := NewGroup(NewTopic("commands", mock.NewDialect(), CommandMessage, DefaultMode))
+	target := NewGroup(NewTopic("events", mock.NewDialect(), EventMessage, DefaultMode))
+
+	_, err := NewBridge(source, target)
+	if err != ErrNoTopic {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
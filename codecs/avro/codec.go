@@ -0,0 +1,223 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/linkedin/goavro/v2"
+)
+
+const (
+	// ContentType represents the content type of avro encoded messages
+	ContentType = "avro/binary"
+	// MagicByte represents the first byte of the Confluent wire format
+	MagicByte byte = 0
+	// HeaderSize represents the size of the magic byte and schema id prefix
+	HeaderSize = 5
+)
+
+// Custom error types
+var (
+	ErrInvalidWireFormat  = errors.New("invalid avro wire format")
+	ErrIncompatibleSchema = errors.New("schema is incompatible with the latest registered schema")
+	ErrMessageRequired    = errors.New("avro values could only be encoded through MarshalMessage")
+)
+
+// UnregisteredError is returned when a message is encoded for which no schema is registered
+type UnregisteredError struct {
+	Action  string
+	Version types.Version
+}
+
+// Error returns the action and version for which no schema is registered
+func (err *UnregisteredError) Error() string {
+	return fmt.Sprintf("no avro schema registered for action %s version %s", err.Action, err.Version)
+}
+
+// Type represents a registered action and version combination
+type Type struct {
+	Action  string
+	Version types.Version
+}
+
+// Schema represents a avro schema registered for a action and version
+type Schema struct {
+	Subject    string
+	codec      *goavro.Codec
+	id         int
+	registered bool
+	mutex      sync.Mutex
+}
+
+// NewCodec constructs a new avro codec using the given schema registry.
+// Messages are encoded in the Confluent wire format, a magic byte and a 4 byte schema id followed by the avro binary payload.
+func NewCodec(registry *Registry) *Codec {
+	return &Codec{
+		registry: registry,
+		schemas:  make(map[Type]*Schema),
+	}
+}
+
+// Codec is a avro message codec used for encoding and decoding messages.
+// The codec could be passed as group option to a commander group.
+type Codec struct {
+	registry *Registry
+	schemas  map[Type]*Schema
+	mutex    sync.RWMutex
+}
+
+// Register registers the given avro schema under the given registry subject for messages with the given action and version.
+// The schema is checked for compatibility and registered inside the schema registry once the first message is produced.
+func (codec *Codec) Register(action string, version int8, subject string, schema string) error {
+	parsed, err := goavro.NewCodec(schema)
+	if err != nil {
+		return err
+	}
+
+	codec.mutex.Lock()
+	defer codec.mutex.Unlock()
+
+	codec.schemas[Type{action, types.Version(version)}] = &Schema{
+		Subject: subject,
+		codec:   parsed,
+	}
+
+	return nil
+}
+
+// Lookup returns the schema registered for the given action and version.
+// The schema is checked for compatibility and registered inside the schema registry if this has not been done before.
+func (codec *Codec) Lookup(action string, version types.Version) (*Schema, error) {
+	codec.mutex.RLock()
+	schema, has := codec.schemas[Type{action, version}]
+	codec.mutex.RUnlock()
+
+	if !has {
+		return nil, &UnregisteredError{action, version}
+	}
+
+	err := codec.register(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// register checks the given schema for compatibility and registers it inside the schema registry.
+// Failed attempts are retried the next time the schema is looked up.
+func (codec *Codec) register(schema *Schema) error {
+	schema.mutex.Lock()
+	defer schema.mutex.Unlock()
+
+	if schema.registered {
+		return nil
+	}
+
+	compatible, err := codec.registry.Compatible(schema.Subject, schema.codec.Schema())
+	if err != nil {
+		return err
+	}
+
+	if !compatible {
+		return ErrIncompatibleSchema
+	}
+
+	id, err := codec.registry.Register(schema.Subject, schema.codec.Schema())
+	if err != nil {
+		return err
+	}
+
+	schema.id = id
+	schema.registered = true
+
+	return nil
+}
+
+// Apply applies the given avro codec to the given group options
+func (codec *Codec) Apply(options *options.GroupOptions) {
+	options.Codec = codec
+}
+
+// ContentType returns the content type of avro encoded messages
+func (codec *Codec) ContentType() string {
+	return ContentType
+}
+
+// Schema returns nil, avro messages are decoded into their native Go representation
+func (codec *Codec) Schema() interface{} {
+	return nil
+}
+
+// Marshal returns a ErrMessageRequired error. The avro schema used to encode a value is
+// determined by the message action and version, values should be encoded through MarshalMessage.
+func (codec *Codec) Marshal(s interface{}) ([]byte, error) {
+	return nil, ErrMessageRequired
+}
+
+// MarshalMessage encodes the given native Go value with the schema registered for the
+// message action and version. The encoded value is stored as the message data.
+func (codec *Codec) MarshalMessage(message *types.Message, s interface{}) error {
+	schema, err := codec.Lookup(message.Action, message.Version)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, HeaderSize)
+	header[0] = MagicByte
+	binary.BigEndian.PutUint32(header[1:], uint32(schema.id))
+
+	bb, err := schema.codec.BinaryFromNative(header, s)
+	if err != nil {
+		return err
+	}
+
+	message.Data = bb
+	return nil
+}
+
+// Unmarshal parses the wire format into s. The given value should be a pointer to a interface,
+// the decoded native Go value is assigned to the interface.
+func (codec *Codec) Unmarshal(data []byte, s interface{}) error {
+	ptr, ok := s.(*interface{})
+	if !ok {
+		return fmt.Errorf("avro values could only be decoded into a *interface{}, got %T", s)
+	}
+
+	native, err := codec.decode(data)
+	if err != nil {
+		return err
+	}
+
+	*ptr = native
+	return nil
+}
+
+// UnmarshalMessage decodes the given message data with the schema referenced by the schema id inside the wire format
+func (codec *Codec) UnmarshalMessage(message *types.Message) (interface{}, error) {
+	return codec.decode(message.Data)
+}
+
+// decode decodes the given Confluent wire formatted data into a native Go value
+func (codec *Codec) decode(data []byte) (interface{}, error) {
+	if len(data) < HeaderSize || data[0] != MagicByte {
+		return nil, ErrInvalidWireFormat
+	}
+
+	id := int(binary.BigEndian.Uint32(data[1:HeaderSize]))
+	schema, err := codec.registry.Schema(id)
+	if err != nil {
+		return nil, err
+	}
+
+	native, _, err := schema.NativeFromBinary(data[HeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	return native, nil
+}
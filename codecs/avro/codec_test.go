@@ -0,0 +1,127 @@
+package avro
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+const user = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"}
+	]
+}`
+
+// NewTestCodec constructs a new avro codec with a registered schema for the "created" action
+func NewTestCodec(t *testing.T, registry *Registry) *Codec {
+	codec := NewCodec(registry)
+
+	err := codec.Register("created", 1, "users-value", user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return codec
+}
+
+// TestMarshalMessage tests if messages are encoded in the Confluent wire format and could be decoded
+func TestMarshalMessage(t *testing.T) {
+	_, registry := NewTestRegistry(t)
+	codec := NewTestCodec(t, registry)
+
+	message := types.NewMessage("created", 1, nil, nil)
+	err := codec.MarshalMessage(message, map[string]interface{}{"name": "john", "age": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Data[0] != MagicByte {
+		t.Fatal("magic byte not set")
+	}
+
+	if binary.BigEndian.Uint32(message.Data[1:HeaderSize]) != 1 {
+		t.Fatal("unexpected schema id")
+	}
+
+	// A seperate registry client is used to ensure that the schema is fetched from the registry
+	decoder := NewCodec(NewRegistry(registry.address, registry.client))
+
+	schema, err := decoder.UnmarshalMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := schema.(map[string]interface{})
+	if record["name"] != "john" || record["age"] != int32(42) {
+		t.Fatalf("unexpected decoded record: %+v", record)
+	}
+
+	var value interface{}
+	err = decoder.Unmarshal(message.Data, &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.(map[string]interface{})["name"] != "john" {
+		t.Fatal("unexpected unmarshalled value")
+	}
+}
+
+// TestMarshalMessageCached tests if schemas are only checked and registered once
+func TestMarshalMessageCached(t *testing.T) {
+	server, registry := NewTestRegistry(t)
+	codec := NewTestCodec(t, registry)
+
+	for i := 0; i < 3; i++ {
+		message := types.NewMessage("created", 1, nil, nil)
+		err := codec.MarshalMessage(message, map[string]interface{}{"name": "john", "age": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// compatibility check and schema registration
+	if server.Requests() != 2 {
+		t.Fatalf("unexpected amount of registry requests: %d", server.Requests())
+	}
+}
+
+// TestMarshalMessageIncompatible tests if a error is returned when producing a incompatible schema
+func TestMarshalMessageIncompatible(t *testing.T) {
+	server, registry := NewTestRegistry(t)
+	registry.Register("users-value", `{"type":"string"}`)
+	server.Incompatible("users-value")
+
+	codec := NewTestCodec(t, registry)
+
+	err := codec.MarshalMessage(types.NewMessage("created", 1, nil, nil), map[string]interface{}{"name": "john", "age": 42})
+	if err != ErrIncompatibleSchema {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestMarshalMessageUnregistered tests if a error is returned when no schema is registered for the message
+func TestMarshalMessageUnregistered(t *testing.T) {
+	_, registry := NewTestRegistry(t)
+	codec := NewTestCodec(t, registry)
+
+	err := codec.MarshalMessage(types.NewMessage("deleted", 1, nil, nil), nil)
+	if _, ok := err.(*UnregisteredError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestUnmarshalInvalidWireFormat tests if a error is returned when decoding data without the Confluent wire format
+func TestUnmarshalInvalidWireFormat(t *testing.T) {
+	_, registry := NewTestRegistry(t)
+	codec := NewTestCodec(t, registry)
+
+	_, err := codec.UnmarshalMessage(types.NewMessage("created", 1, nil, []byte("{}")))
+	if err != ErrInvalidWireFormat {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+const (
+	// RegistryContentType represents the content type of schema registry requests
+	RegistryContentType = "application/vnd.schemaregistry.v1+json"
+)
+
+// RegistryError is returned when the schema registry responded with a unexpected status code
+type RegistryError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+// Error returns the registry error code and message
+func (err *RegistryError) Error() string {
+	return fmt.Sprintf("schema registry responded with status %d (%d): %s", err.StatusCode, err.Code, err.Message)
+}
+
+// NewRegistry constructs a new schema registry client for the given registry address.
+// If no http client is given is the http.DefaultClient used.
+func NewRegistry(address string, client *http.Client) *Registry {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Registry{
+		address: strings.TrimSuffix(address, "/"),
+		client:  client,
+		schemas: make(map[int]*goavro.Codec),
+		ids:     make(map[string]int),
+	}
+}
+
+// Registry is a Confluent compatible schema registry client.
+// Looked up and registered schemas are cached.
+type Registry struct {
+	address string
+	client  *http.Client
+	schemas map[int]*goavro.Codec
+	ids     map[string]int
+	mutex   sync.RWMutex
+}
+
+type schemaRequest struct {
+	Schema string `json:"schema"`
+}
+
+type schemaResponse struct {
+	ID     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+type compatibilityResponse struct {
+	Compatible bool `json:"is_compatible"`
+}
+
+// Register registers the given schema under the given subject and returns the schema id.
+// The schema id is cached, registering the same schema for the same subject twice only results in a single request.
+func (registry *Registry) Register(subject string, schema string) (int, error) {
+	key := subject + ":" + schema
+
+	registry.mutex.RLock()
+	id, has := registry.ids[key]
+	registry.mutex.RUnlock()
+
+	if has {
+		return id, nil
+	}
+
+	response := schemaResponse{}
+	err := registry.request(http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schemaRequest{schema}, &response)
+	if err != nil {
+		return 0, err
+	}
+
+	registry.mutex.Lock()
+	registry.ids[key] = response.ID
+	registry.mutex.Unlock()
+
+	return response.ID, nil
+}
+
+// Compatible checks if the given schema is compatible with the latest schema registered under the given subject.
+// A schema is compatible if no schema has been registered under the given subject.
+func (registry *Registry) Compatible(subject string, schema string) (bool, error) {
+	response := compatibilityResponse{}
+	err := registry.request(http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", schemaRequest{schema}, &response)
+	if err != nil {
+		if err, ok := err.(*RegistryError); ok && err.StatusCode == http.StatusNotFound {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return response.Compatible, nil
+}
+
+// Schema returns the avro codec of the schema registered with the given id.
+// Schemas are cached once looked up.
+func (registry *Registry) Schema(id int) (*goavro.Codec, error) {
+	registry.mutex.RLock()
+	codec, has := registry.schemas[id]
+	registry.mutex.RUnlock()
+
+	if has {
+		return codec, nil
+	}
+
+	response := schemaResponse{}
+	err := registry.request(http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err = goavro.NewCodec(response.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.mutex.Lock()
+	registry.schemas[id] = codec
+	registry.mutex.Unlock()
+
+	return codec, nil
+}
+
+// request performs a new request to the schema registry and decodes the response body into the given value
+func (registry *Registry) request(method string, path string, body interface{}, v interface{}) error {
+	payload := &bytes.Buffer{}
+	if body != nil {
+		err := json.NewEncoder(payload).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, registry.address+path, payload)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", RegistryContentType)
+	req.Header.Set("Accept", RegistryContentType)
+
+	res, err := registry.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		failure := &RegistryError{StatusCode: res.StatusCode}
+		json.NewDecoder(res.Body).Decode(failure)
+		return failure
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
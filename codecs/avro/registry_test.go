@@ -0,0 +1,173 @@
+package avro
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestRegistry represents a in-memory Confluent compatible schema registry stand-in
+type TestRegistry struct {
+	schemas      []string
+	subjects     map[string][]int
+	incompatible map[string]bool
+	requests     int32
+	mutex        sync.Mutex
+}
+
+// Incompatible marks all schemas checked against the given subject as incompatible
+func (registry *TestRegistry) Incompatible(subject string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.incompatible[subject] = true
+}
+
+// Requests returns the amount of requests received by the registry
+func (registry *TestRegistry) Requests() int {
+	return int(atomic.LoadInt32(&registry.requests))
+}
+
+// ServeHTTP handles the schema registry requests
+func (registry *TestRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&registry.requests, 1)
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	w.Header().Set("Content-Type", RegistryContentType)
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	request := schemaRequest{}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	switch {
+	case r.Method == http.MethodPost && len(path) == 3 && path[0] == "subjects" && path[2] == "versions":
+		for _, id := range registry.subjects[path[1]] {
+			if registry.schemas[id-1] == request.Schema {
+				json.NewEncoder(w).Encode(schemaResponse{ID: id})
+				return
+			}
+		}
+
+		registry.schemas = append(registry.schemas, request.Schema)
+		id := len(registry.schemas)
+		registry.subjects[path[1]] = append(registry.subjects[path[1]], id)
+
+		json.NewEncoder(w).Encode(schemaResponse{ID: id})
+	case r.Method == http.MethodGet && len(path) == 3 && path[0] == "schemas" && path[1] == "ids":
+		id, _ := strconv.Atoi(path[2])
+		if id < 1 || id > len(registry.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(RegistryError{Code: 40403, Message: "Schema not found"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(schemaResponse{Schema: registry.schemas[id-1]})
+	case r.Method == http.MethodPost && len(path) == 5 && path[0] == "compatibility":
+		if len(registry.subjects[path[2]]) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(RegistryError{Code: 40401, Message: "Subject not found"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(compatibilityResponse{Compatible: !registry.incompatible[path[2]]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// NewTestRegistry constructs a new schema registry stand-in served over http and a registry client.
+// The registry server is closed once the test is completed.
+func NewTestRegistry(t *testing.T) (*TestRegistry, *Registry) {
+	registry := &TestRegistry{
+		subjects:     make(map[string][]int),
+		incompatible: make(map[string]bool),
+	}
+
+	server := httptest.NewServer(registry)
+	t.Cleanup(server.Close)
+
+	return registry, NewRegistry(server.URL, server.Client())
+}
+
+// TestRegistryRegister tests if able to register and look up schemas and if the results are cached
+func TestRegistryRegister(t *testing.T) {
+	server, registry := NewTestRegistry(t)
+	schema := `{"type":"string"}`
+
+	id, err := registry.Register("events-value", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := registry.Register("events-value", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != again {
+		t.Fatalf("unexpected schema id %d, expected %d", again, id)
+	}
+
+	for i := 0; i < 2; i++ {
+		codec, err := registry.Schema(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if codec.Schema() != schema {
+			t.Fatalf("unexpected schema: %s", codec.Schema())
+		}
+	}
+
+	if server.Requests() != 2 {
+		t.Fatalf("unexpected amount of registry requests: %d", server.Requests())
+	}
+}
+
+// TestRegistryCompatible tests if schemas of unknown subjects are reported as compatible
+func TestRegistryCompatible(t *testing.T) {
+	server, registry := NewTestRegistry(t)
+
+	compatible, err := registry.Compatible("events-value", `{"type":"string"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !compatible {
+		t.Fatal("schema of a unknown subject reported as incompatible")
+	}
+
+	registry.Register("events-value", `{"type":"string"}`)
+	server.Incompatible("events-value")
+
+	compatible, err = registry.Compatible("events-value", `{"type":"long"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compatible {
+		t.Fatal("incompatible schema reported as compatible")
+	}
+}
+
+// TestRegistryError tests if unexpected registry responses are returned as registry errors
+func TestRegistryError(t *testing.T) {
+	_, registry := NewTestRegistry(t)
+
+	_, err := registry.Schema(42)
+	failure, ok := err.(*RegistryError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if failure.StatusCode != http.StatusNotFound || failure.Code != 40403 {
+		t.Fatalf("unexpected registry error: %v", failure)
+	}
+}
@@ -0,0 +1,62 @@
+package cbor
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/schema"
+)
+
+const (
+	// ContentType represents the content type of CBOR encoded messages
+	ContentType = "application/cbor"
+)
+
+// decoder decodes CBOR maps of unknown types as map[string]interface{} to match the JSON and MessagePack codecs
+var decoder, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+// NewCodec constructs a new CBOR codec
+func NewCodec() *Codec {
+	return &Codec{}
+}
+
+// WithCBORCodec returns a GroupOption that configures the CBOR codec as group codec
+func WithCBORCodec() options.GroupOption {
+	return NewCodec()
+}
+
+// Codec is a CBOR message codec used for encoding and decoding messages.
+// All messages are decoded as map[string]interface{} by default.
+// The codec could be passed as group option to a commander group.
+type Codec struct {
+}
+
+// Schema returns the default schema implementation for the CBOR codec
+func (codec *Codec) Schema() interface{} {
+	return make(map[string]interface{})
+}
+
+// Apply applies the given CBOR codec to the given group options
+func (codec *Codec) Apply(options *options.GroupOptions) {
+	options.Codec = codec
+}
+
+// ContentType returns the content type of CBOR encoded messages
+func (codec *Codec) ContentType() string {
+	return ContentType
+}
+
+// Marshal returns the wire format of s.
+func (codec *Codec) Marshal(s interface{}) ([]byte, error) {
+	return cbor.Marshal(s)
+}
+
+// Unmarshal parses the wire format into s.
+func (codec *Codec) Unmarshal(data []byte, s interface{}) error {
+	return schema.Unmarshal(s, func(v interface{}) error {
+		return decoder.Unmarshal(data, v)
+	})
+}
@@ -0,0 +1,147 @@
+package codecs_test
+
+import (
+	"testing"
+
+	"github.com/jeroenrinzema/commander/codecs/cbor"
+	"github.com/jeroenrinzema/commander/codecs/msgpack"
+	"github.com/jeroenrinzema/commander/internal/options"
+)
+
+// TelemetryCommand represents a telemetry command used to test and benchmark the codecs.
+// All codecs encode the same command to compare their performance and message size.
+type TelemetryCommand struct {
+	Device      string    `json:"device" msgpack:"device" cbor:"device"`
+	Timestamp   int64     `json:"timestamp" msgpack:"timestamp" cbor:"timestamp"`
+	Temperature float64   `json:"temperature" msgpack:"temperature" cbor:"temperature"`
+	Online      bool      `json:"online" msgpack:"online" cbor:"online"`
+	Readings    []float64 `json:"readings" msgpack:"readings" cbor:"readings"`
+}
+
+var telemetry = TelemetryCommand{
+	Device:      "sensor-42",
+	Timestamp:   1571234567,
+	Temperature: 21.5,
+	Online:      true,
+	Readings:    []float64{20.1, 20.8, 21.5, 21.2, 20.9},
+}
+
+// Codec represents a codec which could be configured as group option and registered by its content type
+type Codec interface {
+	options.Codec
+	options.GroupOption
+	options.ContentTypeCodec
+}
+
+// codecs contains the codecs compared in the tests and benchmarks
+var codecs = []struct {
+	name   string
+	codec  Codec
+	option options.GroupOption
+}{
+	{"json", &options.JSONCodec{}, options.WithJSONCodec()},
+	{"msgpack", msgpack.NewCodec(), msgpack.WithMsgPackCodec()},
+	{"cbor", cbor.NewCodec(), cbor.WithCBORCodec()},
+}
+
+// TestCodecDefaultSchema tests if values are decoded into the codec default schema
+func TestCodecDefaultSchema(t *testing.T) {
+	for _, test := range codecs {
+		bb, err := test.codec.Marshal(telemetry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		schema := test.codec.Schema()
+		err = test.codec.Unmarshal(bb, &schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, ok := schema.(map[string]interface{})
+		if !ok {
+			t.Fatalf("unexpected %s schema type: %T", test.name, schema)
+		}
+
+		if decoded["device"] != telemetry.Device || decoded["online"] != telemetry.Online {
+			t.Fatalf("unexpected %s decoded schema: %+v", test.name, decoded)
+		}
+	}
+}
+
+// TestCodecStruct tests if values are decoded into the given struct
+func TestCodecStruct(t *testing.T) {
+	for _, test := range codecs {
+		bb, err := test.codec.Marshal(telemetry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := TelemetryCommand{}
+		err = test.codec.Unmarshal(bb, &decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Device != telemetry.Device || decoded.Temperature != telemetry.Temperature || len(decoded.Readings) != len(telemetry.Readings) {
+			t.Fatalf("unexpected %s decoded value: %+v", test.name, decoded)
+		}
+	}
+}
+
+// TestCodecGroupOption tests if the codec group options configure the group codec
+func TestCodecGroupOption(t *testing.T) {
+	for _, test := range codecs {
+		result := options.NewGroupOptions([]options.GroupOption{test.option})
+
+		typed, ok := result.Codec.(Codec)
+		if !ok || typed.ContentType() != test.codec.ContentType() {
+			t.Fatalf("unexpected %s group codec: %T", test.name, result.Codec)
+		}
+	}
+}
+
+// TestCodecContentType tests if the codecs are registered by their content type
+func TestCodecContentType(t *testing.T) {
+	registry := options.Codecs{}
+
+	for _, test := range codecs {
+		registry.Register(test.codec)
+
+		if registry[test.codec.ContentType()] == nil {
+			t.Fatalf("%s codec not registered by its content type", test.name)
+		}
+	}
+}
+
+// BenchmarkCodecMarshal compares the encoding performance and message size of the codecs
+func BenchmarkCodecMarshal(b *testing.B) {
+	for _, test := range codecs {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			var bb []byte
+			for i := 0; i < b.N; i++ {
+				bb, _ = test.codec.Marshal(telemetry)
+			}
+
+			b.ReportMetric(float64(len(bb)), "bytes/msg")
+		})
+	}
+}
+
+// BenchmarkCodecUnmarshal compares the decoding performance of the codecs
+func BenchmarkCodecUnmarshal(b *testing.B) {
+	for _, test := range codecs {
+		bb, _ := test.codec.Marshal(telemetry)
+
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				schema := test.codec.Schema()
+				test.codec.Unmarshal(bb, &schema)
+			}
+		})
+	}
+}
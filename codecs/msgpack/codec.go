@@ -0,0 +1,55 @@
+package msgpack
+
+import (
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/schema"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// ContentType represents the content type of MessagePack encoded messages
+	ContentType = "application/msgpack"
+)
+
+// NewCodec constructs a new MessagePack codec
+func NewCodec() *Codec {
+	return &Codec{}
+}
+
+// WithMsgPackCodec returns a GroupOption that configures the MessagePack codec as group codec
+func WithMsgPackCodec() options.GroupOption {
+	return NewCodec()
+}
+
+// Codec is a MessagePack message codec used for encoding and decoding messages.
+// All messages are decoded as map[string]interface{} by default.
+// The codec could be passed as group option to a commander group.
+type Codec struct {
+}
+
+// Schema returns the default schema implementation for the MessagePack codec
+func (codec *Codec) Schema() interface{} {
+	return make(map[string]interface{})
+}
+
+// Apply applies the given MessagePack codec to the given group options
+func (codec *Codec) Apply(options *options.GroupOptions) {
+	options.Codec = codec
+}
+
+// ContentType returns the content type of MessagePack encoded messages
+func (codec *Codec) ContentType() string {
+	return ContentType
+}
+
+// Marshal returns the wire format of s.
+func (codec *Codec) Marshal(s interface{}) ([]byte, error) {
+	return msgpack.Marshal(s)
+}
+
+// Unmarshal parses the wire format into s.
+func (codec *Codec) Unmarshal(data []byte, s interface{}) error {
+	return schema.Unmarshal(s, func(v interface{}) error {
+		return msgpack.Unmarshal(data, v)
+	})
+}
@@ -0,0 +1,132 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ContentType represents the content type of protobuf encoded messages
+	ContentType = "application/x-protobuf"
+)
+
+var (
+	// ErrNotProtoMessage is returned when the given value is not a protobuf message
+	ErrNotProtoMessage = errors.New("value is not a proto message")
+)
+
+// UnregisteredError is returned when a message is decoded for which no protobuf message type is registered
+type UnregisteredError struct {
+	Action  string
+	Version types.Version
+}
+
+// Error returns the action and version for which no message type is registered
+func (err *UnregisteredError) Error() string {
+	return fmt.Sprintf("no proto message registered for action %s version %s", err.Action, err.Version)
+}
+
+// Type represents a registered action and version combination
+type Type struct {
+	Action  string
+	Version types.Version
+}
+
+// NewCodec constructs a new protobuf codec. Messages are decoded into the
+// protobuf message types registered for the action and version of the message.
+func NewCodec() *Codec {
+	return &Codec{
+		registry: make(map[Type]proto.Message),
+	}
+}
+
+// Codec is a protobuf message codec used for encoding and decoding messages.
+// The codec could be passed as group option to a commander group.
+type Codec struct {
+	registry map[Type]proto.Message
+	mutex    sync.RWMutex
+}
+
+// Register registers the given protobuf message type for the given action and version.
+// Consumed messages with the given action and version are decoded into a new instance of the message type.
+func (codec *Codec) Register(action string, version int8, message proto.Message) {
+	codec.mutex.Lock()
+	defer codec.mutex.Unlock()
+
+	codec.registry[Type{action, types.Version(version)}] = message
+}
+
+// Lookup returns a new instance of the protobuf message type registered for the given action and version.
+// A UnregisteredError is returned if no message type is registered.
+func (codec *Codec) Lookup(action string, version types.Version) (proto.Message, error) {
+	codec.mutex.RLock()
+	defer codec.mutex.RUnlock()
+
+	message, has := codec.registry[Type{action, version}]
+	if !has {
+		return nil, &UnregisteredError{action, version}
+	}
+
+	return message.ProtoReflect().New().Interface(), nil
+}
+
+// Apply applies the given protobuf codec to the given group options
+func (codec *Codec) Apply(options *options.GroupOptions) {
+	options.Codec = codec
+}
+
+// ContentType returns the content type of protobuf encoded messages
+func (codec *Codec) ContentType() string {
+	return ContentType
+}
+
+// Schema returns nil, protobuf messages are decoded into the message type registered for their action and version
+func (codec *Codec) Schema() interface{} {
+	return nil
+}
+
+// Marshal returns the wire format of s. A ErrNotProtoMessage is returned if s is not a proto.Message.
+func (codec *Codec) Marshal(s interface{}) ([]byte, error) {
+	message, ok := s.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return proto.Marshal(message)
+}
+
+// Unmarshal parses the wire format into s. The given value should be a proto.Message
+// or a pointer to a interface containing a proto.Message.
+func (codec *Codec) Unmarshal(data []byte, s interface{}) error {
+	if ptr, ok := s.(*interface{}); ok {
+		s = *ptr
+	}
+
+	message, ok := s.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	return proto.Unmarshal(data, message)
+}
+
+// UnmarshalMessage decodes the given message into a new instance of the protobuf
+// message type registered for the message action and version.
+func (codec *Codec) UnmarshalMessage(message *types.Message) (interface{}, error) {
+	schema, err := codec.Lookup(message.Action, message.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = proto.Unmarshal(message.Data, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,143 @@
+package protobuf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander"
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// NewTestCodec constructs a new protobuf codec with registered message types for the "created" action
+func NewTestCodec() *Codec {
+	codec := NewCodec()
+	codec.Register("created", 1, &wrapperspb.StringValue{})
+	codec.Register("created", 2, &timestamppb.Timestamp{})
+
+	return codec
+}
+
+// TestMarshalUnmarshal tests if able to marshal and unmarshal a proto message
+func TestMarshalUnmarshal(t *testing.T) {
+	codec := NewTestCodec()
+
+	bb, err := codec.Marshal(wrapperspb.String("commander"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := &wrapperspb.StringValue{}
+	var schema interface{} = value
+
+	err = codec.Unmarshal(bb, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.GetValue() != "commander" {
+		t.Fatalf("unexpected value: %s", value.GetValue())
+	}
+
+	_, err = codec.Marshal(map[string]interface{}{})
+	if err != ErrNotProtoMessage {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestUnmarshalMessage tests if messages are decoded into the type registered for their action and version
+func TestUnmarshalMessage(t *testing.T) {
+	codec := NewTestCodec()
+	now := timestamppb.New(time.Now())
+
+	bb, err := proto.Marshal(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := codec.UnmarshalMessage(types.NewMessage("created", 2, nil, bb))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp, ok := schema.(*timestamppb.Timestamp)
+	if !ok {
+		t.Fatalf("unexpected schema type: %T", schema)
+	}
+
+	if !proto.Equal(timestamp, now) {
+		t.Fatal("unexpected decoded timestamp")
+	}
+
+	_, err = codec.UnmarshalMessage(types.NewMessage("deleted", 1, nil, bb))
+	if _, ok := err.(*UnregisteredError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestGroupHandle tests if consumed messages are decoded by a group using the protobuf codec
+// and if decoding failures are reported to the handler.
+func TestGroupHandle(t *testing.T) {
+	recorder := mock.NewRecorder()
+	dialect := mock.NewDialect(mock.WithRecorder(recorder), mock.WithSynchronousDelivery())
+	group := commander.NewGroup(
+		NewTestCodec(),
+		commander.NewTopic("events", dialect, commander.EventMessage, commander.DefaultMode),
+	)
+
+	client, err := commander.NewClient(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	type result struct {
+		schema interface{}
+		err    error
+	}
+
+	results := make(chan result, 2)
+	group.HandleFunc(commander.EventMessage, "created", func(message *commander.Message, writer commander.Writer) {
+		results <- result{message.Schema(), message.SchemaError()}
+	})
+
+	message := commander.NewMessage("created", 1, nil, nil)
+	err = group.Encode(message, wrapperspb.String("commander"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = group.ProduceEvent(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = group.ProduceEvent(commander.NewMessage("created", 1, nil, []byte{0xff}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := <-results
+	if decoded.err != nil {
+		t.Fatal(decoded.err)
+	}
+
+	if decoded.schema.(*wrapperspb.StringValue).GetValue() != "commander" {
+		t.Fatal("unexpected decoded schema")
+	}
+
+	invalid := <-results
+	if invalid.err == nil {
+		t.Fatal("expected a schema error for invalid data")
+	}
+
+	contentType, _ := metadata.ContentTypeFromContext(recorder.Messages()[0].Ctx())
+	if contentType != ContentType {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+}
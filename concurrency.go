@@ -0,0 +1,112 @@
+package commander
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+)
+
+// dispatcher passes consumed messages to the handler callback according to the concurrency options of the handler.
+// Messages are handled one at a time by the consuming goroutine if no concurrency is configured.
+// Consumed messages are resolved once handled, a concurrency slot is held till the handler returns.
+// Dialects delivering the next message once the previous message is resolved are therefore not handled in parallel.
+type dispatcher struct {
+	group   *Group
+	options *options.HandlerOptions
+	slots   chan struct{}
+	workers []chan *Message
+	closed  bool
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+}
+
+// newDispatcher constructs a new dispatcher for the given handler options.
+// If key ordering is enabled is a worker started for every concurrency slot.
+// The dispatcher should be closed once no more messages are dispatched.
+func newDispatcher(group *Group, options *options.HandlerOptions) *dispatcher {
+	dispatcher := &dispatcher{
+		group:   group,
+		options: options,
+	}
+
+	if options.Concurrency < 2 {
+		return dispatcher
+	}
+
+	if !options.KeyOrdering {
+		dispatcher.slots = make(chan struct{}, options.Concurrency)
+		return dispatcher
+	}
+
+	dispatcher.workers = make([]chan *Message, options.Concurrency)
+	for index := range dispatcher.workers {
+		messages := make(chan *Message)
+		dispatcher.workers[index] = messages
+
+		dispatcher.wg.Add(1)
+		go func() {
+			defer dispatcher.wg.Done()
+			for message := range messages {
+				group.handle(message, options)
+			}
+		}()
+	}
+
+	return dispatcher
+}
+
+// dispatch passes the given message to the handler callback.
+// The method blocks till a worker or concurrency slot is available.
+// The given message is resolved once it has been handled by the handler callback.
+// False is returned if the dispatcher is closed, the message is then not resolved.
+func (dispatcher *dispatcher) dispatch(message *Message) bool {
+	dispatcher.mutex.RLock()
+	defer dispatcher.mutex.RUnlock()
+
+	if dispatcher.closed {
+		return false
+	}
+
+	switch {
+	case dispatcher.workers != nil:
+		hash := fnv.New32a()
+		hash.Write(message.Key)
+
+		dispatcher.workers[hash.Sum32()%uint32(len(dispatcher.workers))] <- message
+	case dispatcher.slots != nil:
+		dispatcher.slots <- struct{}{}
+		dispatcher.wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-dispatcher.slots
+				dispatcher.wg.Done()
+			}()
+
+			dispatcher.group.handle(message, dispatcher.options)
+		}()
+	default:
+		dispatcher.group.handle(message, dispatcher.options)
+	}
+
+	return true
+}
+
+// close stops the workers once the messages being handled are handled.
+// The dispatcher awaits the message being dispatched before it is closed.
+func (dispatcher *dispatcher) close() {
+	dispatcher.mutex.Lock()
+	if dispatcher.closed {
+		dispatcher.mutex.Unlock()
+		return
+	}
+
+	dispatcher.closed = true
+	for _, messages := range dispatcher.workers {
+		close(messages)
+	}
+	dispatcher.mutex.Unlock()
+
+	dispatcher.wg.Wait()
+}
@@ -0,0 +1,279 @@
+package commander
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// StubDialect is a in-memory dialect delivering published messages without awaiting the previous message to be resolved
+type StubDialect struct {
+	subscriptions map[<-chan *types.Message]chan *types.Message
+	mutex         sync.Mutex
+}
+
+// NewStubDialect constructs a new stub dialect
+func NewStubDialect() *StubDialect {
+	return &StubDialect{
+		subscriptions: make(map[<-chan *types.Message]chan *types.Message),
+	}
+}
+
+func (dialect *StubDialect) Consumer() types.Consumer { return dialect }
+func (dialect *StubDialect) Producer() types.Producer { return dialect }
+func (dialect *StubDialect) Healthy() bool            { return true }
+func (dialect *StubDialect) Open([]types.Topic) error { return nil }
+func (dialect *StubDialect) Close() error             { return nil }
+
+// Subscribe creates a new subscription receiving all published messages
+func (dialect *StubDialect) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	dialect.mutex.Lock()
+	defer dialect.mutex.Unlock()
+
+	messages := make(chan *types.Message, 100)
+	dialect.subscriptions[messages] = messages
+	return messages, nil
+}
+
+// Unsubscribe closes the given subscription
+func (dialect *StubDialect) Unsubscribe(sub <-chan *types.Message) error {
+	dialect.mutex.Lock()
+	defer dialect.mutex.Unlock()
+
+	messages, has := dialect.subscriptions[sub]
+	if has {
+		delete(dialect.subscriptions, sub)
+		close(messages)
+	}
+
+	return nil
+}
+
+// Publish delivers the given message to all subscriptions
+func (dialect *StubDialect) Publish(message *types.Message) error {
+	dialect.mutex.Lock()
+	defer dialect.mutex.Unlock()
+
+	for _, messages := range dialect.subscriptions {
+		messages <- message
+	}
+
+	return nil
+}
+
+// TestHandleConcurrency tests if up to the configured amount of messages are handled in parallel
+func TestHandleConcurrency(t *testing.T) {
+	group := NewGroup(
+		NewTopic("commands", NewStubDialect(), CommandMessage, DefaultMode),
+	)
+	counter := &InFlight{}
+	release := make(chan struct{})
+
+	mutex := sync.Mutex{}
+	handled := 0
+
+	closing, err := group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithConcurrency(3),
+		WithInFlight(counter),
+		WithCallback(func(message *Message, writer Writer) {
+			<-release
+
+			mutex.Lock()
+			handled++
+			mutex.Unlock()
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer closing()
+
+	for i := 0; i < 6; i++ {
+		err := group.ProduceCommand(types.NewMessage("created", 1, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutil.Eventually(t, time.Second, func() bool { return counter.Count() == 3 })
+
+	time.Sleep(10 * time.Millisecond)
+	if counter.Count() != 3 || group.InFlight() != 3 {
+		t.Fatalf("unexpected amount of in-flight messages: %d", counter.Count())
+	}
+
+	close(release)
+
+	testutil.Eventually(t, time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return handled == 6
+	})
+
+	testutil.Eventually(t, time.Second, func() bool { return counter.Count() == 0 && group.InFlight() == 0 })
+}
+
+// TestHandleSequential tests if messages are handled one at a time if no concurrency is configured
+func TestHandleSequential(t *testing.T) {
+	group := NewGroup(
+		NewTopic("commands", mock.NewDialect(), CommandMessage, DefaultMode),
+	)
+	counter := &InFlight{}
+
+	mutex := sync.Mutex{}
+	handled := 0
+	parallel := int64(0)
+
+	closing, err := group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithInFlight(counter),
+		WithCallback(func(message *Message, writer Writer) {
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			handled++
+			if counter.Count() > parallel {
+				parallel = counter.Count()
+			}
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer closing()
+
+	for i := 0; i < 5; i++ {
+		group.ProduceCommand(types.NewMessage("created", 1, nil, nil))
+	}
+
+	testutil.Eventually(t, time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return handled == 5
+	})
+
+	if parallel != 1 {
+		t.Fatalf("unexpected amount of messages handled in parallel: %d", parallel)
+	}
+}
+
+// TestHandleKeyOrdering tests if messages with the same key are handled in order while being handled concurrently
+func TestHandleKeyOrdering(t *testing.T) {
+	group := NewGroup(
+		NewTopic("commands", NewStubDialect(), CommandMessage, DefaultMode),
+	)
+	keys := []string{"a", "b", "c"}
+
+	mutex := sync.Mutex{}
+	handled := map[string][]int{}
+	total := 0
+
+	closing, err := group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithConcurrency(4),
+		WithKeyOrdering(),
+		WithCallback(func(message *Message, writer Writer) {
+			sequence, _ := strconv.Atoi(string(message.Data))
+			time.Sleep(time.Duration(sequence%3) * time.Millisecond)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			handled[string(message.Key)] = append(handled[string(message.Key)], sequence)
+			total++
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer closing()
+
+	for i := 0; i < 30; i++ {
+		key := []byte(keys[i%len(keys)])
+		err := group.ProduceCommand(types.NewMessage("created", 1, key, []byte(strconv.Itoa(i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutil.Eventually(t, time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return total == 30
+	})
+
+	for key, sequences := range handled {
+		for index := 1; index < len(sequences); index++ {
+			if sequences[index] < sequences[index-1] {
+				t.Fatalf("messages of key %s handled out of order: %v", key, sequences)
+			}
+		}
+	}
+}
+
+// TestHandleConcurrencyResolve tests if concurrently handled messages are resolved once handled by the handler
+func TestHandleConcurrencyResolve(t *testing.T) {
+	for _, key := range []bool{false, true} {
+		group := NewGroup(
+			NewTopic("commands", NewStubDialect(), CommandMessage, DefaultMode),
+		)
+
+		definitions := []options.HandlerOption{
+			WithAction("created"),
+			WithMessageType(CommandMessage),
+			WithConcurrency(2),
+			WithCallback(func(message *Message, writer Writer) {
+				if string(message.Data) == "nack" {
+					message.Nack()
+				}
+			}),
+		}
+
+		if key {
+			definitions = append(definitions, WithKeyOrdering())
+		}
+
+		closing, err := group.HandleContext(definitions...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		acked := types.NewMessage("created", 1, nil, nil)
+		nacked := types.NewMessage("created", 1, nil, []byte("nack"))
+
+		for _, message := range []*Message{acked, nacked} {
+			err := group.ProduceCommand(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if acked.Finally() != nil {
+			t.Fatal("handled message not acknowledged")
+		}
+
+		if nacked.Finally() == nil {
+			t.Fatal("negative acknowledgement of the handler not propagated to the consumed message")
+		}
+
+		closing()
+	}
+}
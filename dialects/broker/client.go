@@ -0,0 +1,144 @@
+package broker
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Custom error types
+var (
+	ErrTimeout      = errors.New("broker request timeout")
+	ErrDisconnected = errors.New("broker connection closed")
+)
+
+// Dial opens a new client connection to the broker server on the given network and address
+func Dial(network string, address string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		conn:     NewConn(conn),
+		timeout:  timeout,
+		pending:  make(map[uint64]chan Frame),
+		handlers: make(map[uint64]func(Frame)),
+		done:     make(chan struct{}),
+	}
+
+	go client.read()
+	return client, nil
+}
+
+// Client represents a broker client connection. Requests are matched with their
+// results by request id, delivered messages are passed to the handler of their subscription.
+type Client struct {
+	conn     *Conn
+	timeout  time.Duration
+	id       uint64
+	pending  map[uint64]chan Frame
+	handlers map[uint64]func(Frame)
+	done     chan struct{}
+	mutex    sync.Mutex
+}
+
+// read reads the frames send by the server till the connection is closed
+func (client *Client) read() {
+	defer close(client.done)
+
+	for {
+		frame, err := client.conn.Read()
+		if err != nil {
+			return
+		}
+
+		client.mutex.Lock()
+		switch frame.Type {
+		case FrameResult:
+			result, has := client.pending[frame.ID]
+			if has {
+				result <- frame
+			}
+		case FrameDeliver:
+			handler, has := client.handlers[frame.Subscription]
+			if has {
+				go handler(frame)
+				break
+			}
+
+			go client.Write(Frame{Type: FrameNack, Delivery: frame.Delivery})
+		default:
+			log.Errorf("unexpected broker frame: %s", frame.Type)
+		}
+		client.mutex.Unlock()
+	}
+}
+
+// Request writes the given request frame and awaits its result. The request id is assigned
+// to the frame before it is written. If a handler is given is it registered as delivery
+// handler for the subscription with the same id as the request.
+func (client *Client) Request(frame Frame, handler func(Frame)) (Frame, error) {
+	result := make(chan Frame, 1)
+
+	client.mutex.Lock()
+	client.id++
+	frame.ID = client.id
+	client.pending[frame.ID] = result
+	if handler != nil {
+		client.handlers[frame.ID] = handler
+	}
+	client.mutex.Unlock()
+
+	defer func() {
+		client.mutex.Lock()
+		delete(client.pending, frame.ID)
+		client.mutex.Unlock()
+	}()
+
+	err := client.conn.Write(frame)
+	if err != nil {
+		return frame, err
+	}
+
+	select {
+	case response := <-result:
+		if response.Error != "" {
+			return response, errors.New(response.Error)
+		}
+
+		return response, nil
+	case <-client.done:
+		return frame, ErrDisconnected
+	case <-time.After(client.timeout):
+		return frame, ErrTimeout
+	}
+}
+
+// Remove removes the delivery handler of the given subscription
+func (client *Client) Remove(subscription uint64) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	delete(client.handlers, subscription)
+}
+
+// Write writes the given frame to the server
+func (client *Client) Write(frame Frame) error {
+	return client.conn.Write(frame)
+}
+
+// Done returns a channel that is closed once the connection is closed
+func (client *Client) Done() <-chan struct{} {
+	return client.done
+}
+
+// Close closes the client connection and awaits till the connection is closed
+func (client *Client) Close() error {
+	err := client.conn.Close()
+	<-client.done
+	return err
+}
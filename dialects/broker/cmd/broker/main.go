@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jeroenrinzema/commander/dialects/broker"
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	network := flag.String("network", "tcp", "network to listen on (tcp or unix)")
+	address := flag.String("address", "127.0.0.1:4050", "address to listen on")
+	retention := flag.Int("retention", -1, "amount of messages retained per topic, 0 retains all messages and -1 disables retention")
+	flag.Parse()
+
+	options := []mock.Option{}
+	if *retention >= 0 {
+		options = append(options, mock.WithRetention(*retention))
+	}
+
+	server := broker.NewServer(options...)
+
+	addr, err := server.Listen(*network, *address)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("commander broker listening on %s://%s", *network, addr)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	err = server.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,61 @@
+package broker
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Default config value's
+var (
+	DefaultTimeout = 5 * time.Second
+)
+
+// Custom error types
+var (
+	ErrUnsupportedNetwork = errors.New("unsupported network, expected tcp or unix")
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Network string
+	Address string
+	Group   string
+	Timeout time.Duration
+}
+
+// NewConfig constructs a Config from the given connection map.
+// The address is expected to be a tcp (tcp://127.0.0.1:4000) or unix (unix:///tmp/commander.sock) URL.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Group:   values[GroupKey],
+		Timeout: DefaultTimeout,
+	}
+
+	address, err := url.Parse(values[AddressKey])
+	if err != nil {
+		return config, err
+	}
+
+	switch address.Scheme {
+	case "tcp":
+		config.Network = address.Scheme
+		config.Address = address.Host
+	case "unix":
+		config.Network = address.Scheme
+		config.Address = address.Path
+	default:
+		return config, ErrUnsupportedNetwork
+	}
+
+	if values[TimeoutKey] != "" {
+		timeout, err := time.ParseDuration(values[TimeoutKey])
+		if err != nil {
+			return config, err
+		}
+
+		config.Timeout = timeout
+	}
+
+	return config, nil
+}
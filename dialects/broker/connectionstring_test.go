@@ -0,0 +1,59 @@
+package broker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("address=%s group=%s timeout=%s", val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		AddressKey,
+		GroupKey,
+		TimeoutKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	conf, err := NewConfig(ConnectionMap{AddressKey: "tcp://127.0.0.1:4050", TimeoutKey: "1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Network != "tcp" || conf.Address != "127.0.0.1:4050" {
+		t.Fatal("Unexpected tcp address")
+	}
+
+	if conf.Timeout != time.Second {
+		t.Fatal("Timeout not set")
+	}
+
+	conf, err = NewConfig(ConnectionMap{AddressKey: "unix:///tmp/commander.sock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Network != "unix" || conf.Address != "/tmp/commander.sock" {
+		t.Fatal("Unexpected unix address")
+	}
+}
+
+// TestNewConfigUnsupportedNetwork tests if a error is returned for a unsupported network
+func TestNewConfigUnsupportedNetwork(t *testing.T) {
+	_, err := NewConfig(ConnectionMap{AddressKey: "udp://127.0.0.1:4050"})
+	if err != ErrUnsupportedNetwork {
+		t.Fatal("unexpected error")
+	}
+}
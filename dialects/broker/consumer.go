@@ -0,0 +1,184 @@
+package broker
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Custom error types
+var (
+	ErrNotConnected = errors.New("broker dialect not opened")
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	id       uint64
+	client   *Client
+	topics   map[string]types.Topic
+	messages chan *types.Message
+	closing  chan struct{}
+	closed   bool
+	mutex    sync.RWMutex
+}
+
+// Deliver delivers the message of the given deliver frame to the subscription. The message is
+// awaited till resolved after which the resolved state is written to the broker server.
+func (subscription *Subscription) Deliver(frame Frame) {
+	if frame.Message == nil {
+		return
+	}
+
+	result := Frame{
+		Type:     FrameNack,
+		Delivery: frame.Delivery,
+	}
+
+	defer func() {
+		subscription.client.Write(result)
+	}()
+
+	topic, has := subscription.topics[frame.Message.Topic]
+	if !has {
+		return
+	}
+
+	message := frame.Message.Message(topic)
+	message.Reset()
+
+	subscription.mutex.RLock()
+	defer subscription.mutex.RUnlock()
+
+	if subscription.closed {
+		return
+	}
+
+	select {
+	case subscription.messages <- message:
+	case <-subscription.closing:
+		return
+	}
+
+	err := message.Finally()
+	if err != nil {
+		return
+	}
+
+	result.Type = FrameAck
+}
+
+// NewConsumer constructs a new broker consumer joining the given consumer group.
+// Subscriptions without a consumer group receive all messages.
+func NewConsumer(group string) *Consumer {
+	return &Consumer{
+		group:         group,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+	}
+}
+
+// Consumer subscribes to topics of the broker server
+type Consumer struct {
+	client        *Client
+	group         string
+	subscriptions map[<-chan *types.Message]*Subscription
+	mutex         sync.RWMutex
+}
+
+// Connect assigns the given broker client to the consumer
+func (consumer *Consumer) Connect(client *Client) {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	consumer.client = client
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	if consumer.client == nil {
+		return nil, ErrNotConnected
+	}
+
+	subscription := &Subscription{
+		client:   consumer.client,
+		topics:   make(map[string]types.Topic, len(topics)),
+		messages: make(chan *types.Message, 0),
+		closing:  make(chan struct{}),
+	}
+
+	for _, topic := range topics {
+		subscription.topics[topic.Name()] = topic
+	}
+
+	response, err := consumer.client.Request(Frame{
+		Type:   FrameSubscribe,
+		Group:  consumer.group,
+		Topics: NewTopicFrames(topics...),
+	}, subscription.Deliver)
+
+	subscription.id = response.ID
+
+	if err != nil {
+		consumer.client.Remove(subscription.id)
+		return nil, err
+	}
+
+	consumer.subscriptions[subscription.messages] = subscription
+	return subscription.messages, nil
+}
+
+// Unsubscribe unsubscribes the given channel from the broker server and closes the channel
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.Lock()
+	subscription, has := consumer.subscriptions[sub]
+	delete(consumer.subscriptions, sub)
+	consumer.mutex.Unlock()
+
+	if !has {
+		return nil
+	}
+
+	return consumer.unsubscribe(subscription)
+}
+
+// unsubscribe unsubscribes and closes the given subscription
+func (consumer *Consumer) unsubscribe(subscription *Subscription) error {
+	subscription.client.Remove(subscription.id)
+	close(subscription.closing)
+
+	subscription.mutex.Lock()
+	subscription.closed = true
+	close(subscription.messages)
+	subscription.mutex.Unlock()
+
+	_, err := subscription.client.Request(Frame{
+		Type:         FrameUnsubscribe,
+		Subscription: subscription.id,
+	}, nil)
+
+	if err == ErrDisconnected {
+		return nil
+	}
+
+	return err
+}
+
+// Close unsubscribes all subscriptions
+func (consumer *Consumer) Close() error {
+	consumer.mutex.Lock()
+	subscriptions := consumer.subscriptions
+	consumer.subscriptions = make(map[<-chan *types.Message]*Subscription)
+	consumer.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		err := consumer.unsubscribe(subscription)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package broker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// WaitIdle awaits till all in-flight messages of the given server have been processed
+func WaitIdle(server *Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return server.Dialect().WaitIdle(ctx)
+}
+
+// TestConsumerConsumption tests if messages produced by one client are consumed by a other client including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	_, address := NewTestServer(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	consumer := NewTestDialect(t, address, "", topic)
+	producer := NewTestDialect(t, address, "", topic)
+
+	messages, err := consumer.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = producer.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+		t.Fatal("message key or data not preserved")
+	}
+
+	if !consumed.EOS || consumed.Status != types.StatusNotFound {
+		t.Fatal("message eos or status not preserved")
+	}
+
+	if consumed.Topic != topic {
+		t.Fatal("unexpected message topic")
+	}
+
+	id, has := metadata.ParentIDFromContext(consumed.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+}
+
+// TestConsumerAck tests if message acknowledgements are propagated to the broker server
+func TestConsumerAck(t *testing.T) {
+	server, address := NewTestServer(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, address, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Await(t, messages).Ack()
+
+	err = WaitIdle(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestConsumerGroup tests if messages are load balanced between clients in the same consumer group
+// and if negative acknowledged messages are redelivered to a other member of the group.
+func TestConsumerGroup(t *testing.T) {
+	_, address := NewTestServer(t)
+
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+	consumed := int32(0)
+	nacked := int32(0)
+
+	for i := 0; i < 2; i++ {
+		dialect := NewTestDialect(t, address, "group=mock", topic)
+		messages, err := dialect.Consumer().Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			for message := range messages {
+				if atomic.CompareAndSwapInt32(&nacked, 0, 1) {
+					message.Nack()
+					continue
+				}
+
+				atomic.AddInt32(&consumed, 1)
+				message.Ack()
+			}
+		}()
+	}
+
+	producer := NewTestDialect(t, address, "")
+
+	for i := 0; i < 10; i++ {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err := producer.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&consumed) < 10 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if atomic.LoadInt32(&consumed) != 10 {
+		t.Fatalf("unexpected amount of consumed messages: %d", consumed)
+	}
+}
+
+// TestConsumerDisconnect tests if subscriptions of a disconnected client are removed
+func TestConsumerDisconnect(t *testing.T) {
+	server, address := NewTestServer(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, address, "", topic)
+
+	_, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Close the connection without unsubscribing
+	dialect.client.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		server.mutex.Lock()
+		sessions := len(server.sessions)
+		server.mutex.Unlock()
+
+		if sessions == 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	producer := NewTestDialect(t, address, "")
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = producer.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = WaitIdle(server)
+	if err != nil {
+		t.Fatal("message delivered to a disconnected client")
+	}
+}
+
+// TestConsumerUnsubscribe tests if the subscription channel is closed once unsubscribed
+func TestConsumerUnsubscribe(t *testing.T) {
+	_, address := NewTestServer(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, address, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dialect.Consumer().Unsubscribe(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, open := <-messages
+	if open {
+		t.Fatal("subscription not closed")
+	}
+}
+
+// TestConsumerSynchronousDelivery tests if a client is able to consume the messages it publishes when the server delivers messages synchronously
+func TestConsumerSynchronousDelivery(t *testing.T) {
+	_, address := NewTestServer(t, mock.WithSynchronousDelivery())
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, address, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := make(chan *types.Message, 1)
+	go func() {
+		for message := range messages {
+			message.Ack()
+			consumed <- message
+		}
+	}()
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	published := make(chan error, 1)
+	go func() {
+		published <- dialect.Producer().Publish(message)
+	}()
+
+	select {
+	case err := <-published:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked by the synchronous delivery to the publishing client")
+	}
+
+	result := testutil.Await(t, consumed)
+	if result.ID != message.ID {
+		t.Fatal("unexpected message consumed")
+	}
+}
@@ -0,0 +1,98 @@
+package broker
+
+import (
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Dialect represents the broker client dialect
+type Dialect struct {
+	Connection Config
+
+	client   *Client
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new broker client dialect.
+// The dialect connects to a broker server over TCP or a Unix socket, allowing multiple
+// processes to exchange messages with the in-memory semantics of the mock dialect.
+// If a group is defined in the connectionstring do the subscriptions join the given consumer group.
+func NewDialect(connectionstring string) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := &Dialect{
+		Connection: connection,
+		consumer:   NewConsumer(connection.Group),
+		producer:   NewProducer(),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open opens a connection to the broker server
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	client, err := Dial(dialect.Connection.Network, dialect.Connection.Address, dialect.Connection.Timeout)
+	if err != nil {
+		return err
+	}
+
+	dialect.client = client
+	dialect.consumer.Connect(client)
+	dialect.producer.Connect(client)
+
+	return nil
+}
+
+// Close closes the broker consumers, producers and connection
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	if dialect.client == nil {
+		return nil
+	}
+
+	return dialect.client.Close()
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	if dialect.client == nil {
+		return false
+	}
+
+	select {
+	case <-dialect.client.Done():
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,100 @@
+package broker
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestServer starts a new broker server listening on a random TCP port.
+// The server is closed once the test is completed.
+func NewTestServer(t *testing.T, definitions ...mock.Option) (*Server, string) {
+	server := NewServer(definitions...)
+
+	addr, err := server.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	return server, "tcp://" + addr.String()
+}
+
+// NewTestDialect constructs and opens a new broker dialect for the given address, connectionstring options and topics
+func NewTestDialect(t *testing.T, address string, options string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect("address=" + address + " " + options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new broker dialect
+func TestNewDialect(t *testing.T) {
+	_, address := NewTestServer(t)
+	dialect := NewTestDialect(t, address, "")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dialect.Healthy() {
+		t.Fatal("closed dialect reported as healthy")
+	}
+}
+
+// TestNewDialectUnixSocket tests if able to connect to a broker server over a unix socket
+func TestNewDialectUnixSocket(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "commander.sock")
+	_, err := server.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "unix://"+path, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Await(t, messages).Ack()
+}
+
+// TestNewDialectNotOpened tests if a error is returned when subscribing before the dialect is opened
+func TestNewDialectNotOpened(t *testing.T) {
+	dialect, err := NewDialect("address=tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = dialect.Consumer().Subscribe(types.NewTopic("events", dialect, types.EventMessage, types.DefaultMode))
+	if err != ErrNotConnected {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
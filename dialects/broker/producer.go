@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewProducer constructs a new broker producer
+func NewProducer() *Producer {
+	return &Producer{}
+}
+
+// Producer publishes messages to the broker server
+type Producer struct {
+	client     *Client
+	production sync.WaitGroup
+}
+
+// Connect assigns the given broker client to the producer
+func (producer *Producer) Connect(client *Client) {
+	producer.client = client
+}
+
+// Publish publishes the given message to the broker server and awaits till the message is published
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	if producer.client == nil {
+		return ErrNotConnected
+	}
+
+	_, err := producer.client.Request(Frame{
+		Type:    FramePublish,
+		Topics:  NewTopicFrames(message.Topic),
+		Message: NewMessageFrame(message),
+	}, nil)
+
+	return err
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Frame types exchanged between the broker server and clients
+const (
+	FrameSubscribe   = "subscribe"
+	FrameUnsubscribe = "unsubscribe"
+	FramePublish     = "publish"
+	FrameResult      = "result"
+	FrameDeliver     = "deliver"
+	FrameAck         = "ack"
+	FrameNack        = "nack"
+)
+
+// Frame represents a single protocol frame. Frames are encoded as JSON objects separated by newlines.
+// Requests (subscribe, unsubscribe and publish) are responded to with a result frame containing the
+// request id. Messages are delivered to subscriptions through deliver frames which are responded
+// to with a ack or nack frame containing the delivery id.
+type Frame struct {
+	Type         string        `json:"type"`
+	ID           uint64        `json:"id,omitempty"`
+	Subscription uint64        `json:"subscription,omitempty"`
+	Delivery     uint64        `json:"delivery,omitempty"`
+	Group        string        `json:"group,omitempty"`
+	Topics       []TopicFrame  `json:"topics,omitempty"`
+	Message      *MessageFrame `json:"message,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// TopicFrame represents a topic definition
+type TopicFrame struct {
+	Name string            `json:"name"`
+	Type types.MessageType `json:"type"`
+	Mode types.TopicMode   `json:"mode"`
+}
+
+// NewTopicFrames constructs topic frames of the given topics
+func NewTopicFrames(topics ...types.Topic) []TopicFrame {
+	frames := make([]TopicFrame, len(topics))
+	for index, topic := range topics {
+		frames[index] = TopicFrame{
+			Name: topic.Name(),
+			Type: topic.Type(),
+			Mode: topic.Mode(),
+		}
+	}
+
+	return frames
+}
+
+// Topic constructs a topic of the topic frame for the given dialect
+func (frame TopicFrame) Topic(dialect types.Dialect) types.Topic {
+	return types.NewTopic(frame.Name, dialect, frame.Type, frame.Mode)
+}
+
+// MessageFrame represents a message including its metadata
+type MessageFrame struct {
+	Topic     string            `json:"topic"`
+	Headers   map[string]string `json:"headers"`
+	Key       []byte            `json:"key,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+// NewMessageFrame constructs a message frame of the given message
+func NewMessageFrame(message *types.Message) *MessageFrame {
+	return &MessageFrame{
+		Topic:     message.Topic.Name(),
+		Headers:   headers.Marshal(message),
+		Key:       message.Key,
+		Data:      message.Data,
+		Timestamp: message.Timestamp.UnixNano(),
+	}
+}
+
+// Message constructs a commander message of the message frame for the given topic
+func (frame *MessageFrame) Message(topic types.Topic) *types.Message {
+	message := &types.Message{
+		Topic:     topic,
+		Key:       frame.Key,
+		Data:      frame.Data,
+		Status:    types.StatusOK,
+		Timestamp: time.Unix(0, frame.Timestamp),
+	}
+
+	message.NewCtx(context.Background())
+	headers.Unmarshal(message, frame.Headers)
+
+	return message
+}
+
+// NewConn constructs a new frame connection of the given network connection
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn:    conn,
+		decoder: json.NewDecoder(bufio.NewReader(conn)),
+		encoder: json.NewEncoder(conn),
+	}
+}
+
+// Conn reads and writes frames from and to a network connection.
+// Frames could be written concurrently, frames should be read from a single goroutine.
+type Conn struct {
+	conn    net.Conn
+	decoder *json.Decoder
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+// Read reads the next frame from the connection
+func (conn *Conn) Read() (Frame, error) {
+	frame := Frame{}
+	err := conn.decoder.Decode(&frame)
+	return frame, err
+}
+
+// Write writes the given frame to the connection
+func (conn *Conn) Write(frame Frame) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	return conn.encoder.Encode(frame)
+}
+
+// Close closes the network connection
+func (conn *Conn) Close() error {
+	return conn.conn.Close()
+}
@@ -0,0 +1,335 @@
+package broker
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Custom error types
+var (
+	ErrUnknownFrame = errors.New("unknown frame type")
+	ErrServerClosed = errors.New("broker server closed")
+)
+
+// NewServer constructs a new broker server backed by a in-memory mock dialect
+// constructed with the given options.
+func NewServer(definitions ...mock.Option) *Server {
+	return &Server{
+		dialect:   mock.NewDialect(definitions...),
+		listeners: make(map[net.Listener]bool),
+		sessions:  make(map[*Session]bool),
+	}
+}
+
+// Server represents a broker server exposing a in-memory mock dialect over the network.
+// Clients connected to the same server exchange messages with the semantics of the mock
+// dialect, including consumer groups, retention and fault injection.
+type Server struct {
+	dialect   *mock.Dialect
+	listeners map[net.Listener]bool
+	sessions  map[*Session]bool
+	closed    bool
+	serving   sync.WaitGroup
+	mutex     sync.Mutex
+}
+
+// Dialect returns the in-memory mock dialect backing the server
+func (server *Server) Dialect() *mock.Dialect {
+	return server.dialect
+}
+
+// Listen starts listening on the given network ("tcp" or "unix") and address.
+// Connections are accepted in the background, the listener address is returned.
+func (server *Server) Listen(network string, address string) (net.Addr, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	go server.Serve(listener)
+	return listener.Addr(), nil
+}
+
+// Serve accepts connections on the given listener till the listener or server is closed
+func (server *Server) Serve(listener net.Listener) error {
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+
+	server.listeners[listener] = true
+	server.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			server.mutex.Lock()
+			defer server.mutex.Unlock()
+
+			delete(server.listeners, listener)
+			if server.closed {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		session := NewSession(server, NewConn(conn))
+
+		server.mutex.Lock()
+		if server.closed {
+			server.mutex.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		server.sessions[session] = true
+		server.serving.Add(1)
+		server.mutex.Unlock()
+
+		go func() {
+			defer server.serving.Done()
+			session.Handle()
+
+			server.mutex.Lock()
+			delete(server.sessions, session)
+			server.mutex.Unlock()
+		}()
+	}
+}
+
+// Close closes all listeners and client sessions and the backing mock dialect
+func (server *Server) Close() error {
+	server.mutex.Lock()
+	server.closed = true
+
+	for listener := range server.listeners {
+		listener.Close()
+	}
+
+	for session := range server.sessions {
+		session.conn.Close()
+	}
+	server.mutex.Unlock()
+
+	server.serving.Wait()
+	return server.dialect.Close()
+}
+
+// NewSession constructs a new client session for the given server and connection
+func NewSession(server *Server, conn *Conn) *Session {
+	return &Session{
+		server:        server,
+		conn:          conn,
+		subscriptions: make(map[uint64]<-chan *types.Message),
+		deliveries:    make(map[uint64]chan bool),
+		closing:       make(chan struct{}),
+	}
+}
+
+// Session represents a connected broker client.
+// Subscriptions made by a client are unsubscribed once the client disconnects,
+// messages awaiting a acknowledgement of the client are then negative acknowledged.
+type Session struct {
+	server        *Server
+	conn          *Conn
+	subscriptions map[uint64]<-chan *types.Message
+	deliveries    map[uint64]chan bool
+	delivery      uint64
+	closing       chan struct{}
+	forwarding    sync.WaitGroup
+	publishing    sync.WaitGroup
+	mutex         sync.Mutex
+}
+
+// Handle reads and handles the frames send by the client till the connection is closed.
+// Published messages are published in the background, the result is written once the message is published.
+func (session *Session) Handle() {
+	defer session.Close()
+
+	for {
+		frame, err := session.conn.Read()
+		if err != nil {
+			return
+		}
+
+		switch frame.Type {
+		case FrameSubscribe:
+			err = session.Subscribe(frame)
+		case FrameUnsubscribe:
+			err = session.Unsubscribe(frame)
+		case FramePublish:
+			// NOTE: messages are published outside of the read loop since publishing a message could await
+			// acknowledgements of this client, which are read by the read loop (ex: synchronous delivery).
+			session.publishing.Add(1)
+			go func(frame Frame) {
+				defer session.publishing.Done()
+
+				err := session.Publish(frame)
+				if err != nil {
+					log.Error(err)
+				}
+			}(frame)
+		case FrameAck, FrameNack:
+			session.Resolve(frame)
+		default:
+			err = session.Respond(frame, ErrUnknownFrame)
+		}
+
+		if err != nil {
+			log.Error(err)
+			return
+		}
+	}
+}
+
+// Respond writes a result frame for the given request frame
+func (session *Session) Respond(frame Frame, err error) error {
+	result := Frame{
+		Type: FrameResult,
+		ID:   frame.ID,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return session.conn.Write(result)
+}
+
+// Subscribe subscribes to the topics of the given subscribe frame. If a group is defined
+// inside the frame joins the subscription the given consumer group.
+func (session *Session) Subscribe(frame Frame) error {
+	topics := make([]types.Topic, len(frame.Topics))
+	for index, topic := range frame.Topics {
+		topics[index] = topic.Topic(session.server.dialect)
+	}
+
+	consumer := session.server.dialect.Consumer().(*mock.Consumer)
+	messages, err := consumer.SubscribeGroup(frame.Group, topics...)
+	if err != nil {
+		return session.Respond(frame, err)
+	}
+
+	session.mutex.Lock()
+	session.subscriptions[frame.ID] = messages
+	session.mutex.Unlock()
+
+	session.forwarding.Add(1)
+	go session.Forward(frame.ID, messages)
+
+	return session.Respond(frame, nil)
+}
+
+// Unsubscribe unsubscribes the subscription of the given unsubscribe frame
+func (session *Session) Unsubscribe(frame Frame) error {
+	session.mutex.Lock()
+	messages, has := session.subscriptions[frame.Subscription]
+	delete(session.subscriptions, frame.Subscription)
+	session.mutex.Unlock()
+
+	if has {
+		session.server.dialect.Consumer().Unsubscribe(messages)
+	}
+
+	return session.Respond(frame, nil)
+}
+
+// Publish publishes the message of the given publish frame
+func (session *Session) Publish(frame Frame) error {
+	if frame.Message == nil {
+		return session.Respond(frame, errors.New("no message defined"))
+	}
+
+	topic := types.NewTopic(frame.Message.Topic, session.server.dialect, 0, types.ProduceMode)
+	if len(frame.Topics) > 0 {
+		topic = frame.Topics[0].Topic(session.server.dialect)
+	}
+
+	message := frame.Message.Message(topic)
+	err := session.server.dialect.Producer().Publish(message)
+	return session.Respond(frame, err)
+}
+
+// Forward delivers the messages of the given subscription to the client. Every message is
+// awaited till acknowledged by the client before the next message is delivered.
+func (session *Session) Forward(subscription uint64, messages <-chan *types.Message) {
+	defer session.forwarding.Done()
+
+	for message := range messages {
+		session.mutex.Lock()
+		session.delivery++
+		delivery := session.delivery
+		resolved := make(chan bool, 1)
+		session.deliveries[delivery] = resolved
+		session.mutex.Unlock()
+
+		err := session.conn.Write(Frame{
+			Type:         FrameDeliver,
+			Subscription: subscription,
+			Delivery:     delivery,
+			Message:      NewMessageFrame(message),
+		})
+
+		ack := false
+		if err == nil {
+			select {
+			case ack = <-resolved:
+			case <-session.closing:
+			}
+		}
+
+		session.mutex.Lock()
+		delete(session.deliveries, delivery)
+		session.mutex.Unlock()
+
+		if ack {
+			message.Ack()
+			continue
+		}
+
+		message.Nack()
+	}
+}
+
+// Resolve resolves the delivery of the given ack or nack frame
+func (session *Session) Resolve(frame Frame) {
+	session.mutex.Lock()
+	resolved, has := session.deliveries[frame.Delivery]
+	session.mutex.Unlock()
+
+	if !has {
+		return
+	}
+
+	select {
+	case resolved <- frame.Type == FrameAck:
+	default:
+	}
+}
+
+// Close unsubscribes all client subscriptions, negative acknowledges all messages
+// awaiting a acknowledgement, awaits the messages being published and closes the connection.
+func (session *Session) Close() error {
+	close(session.closing)
+
+	session.mutex.Lock()
+	subscriptions := session.subscriptions
+	session.subscriptions = make(map[uint64]<-chan *types.Message)
+	session.mutex.Unlock()
+
+	for _, messages := range subscriptions {
+		session.server.dialect.Consumer().Unsubscribe(messages)
+	}
+
+	session.forwarding.Wait()
+	session.publishing.Wait()
+	return session.conn.Close()
+}
@@ -0,0 +1,119 @@
+package filelog
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Initial offset key values
+const (
+	OffsetNewest = "newest"
+	OffsetOldest = "oldest"
+)
+
+// Sync policies defining when written records are flushed to disk
+const (
+	SyncAlways   = "always"
+	SyncInterval = "interval"
+	SyncNever    = "never"
+)
+
+// Default config value's
+var (
+	DefaultSegmentSize       int64 = 16 * 1024 * 1024
+	DefaultSyncInterval            = time.Second
+	DefaultRetentionInterval       = time.Minute
+	DefaultPollInterval            = time.Second
+)
+
+// Custom error types
+var (
+	ErrInvalidInitialOffset = errors.New("invalid initial offset, expected newest or oldest")
+	ErrInvalidSyncPolicy    = errors.New("invalid sync policy, expected always, interval or never")
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Path              string
+	Group             string
+	SegmentSize       int64
+	Sync              string
+	SyncInterval      time.Duration
+	RetentionSize     int64
+	RetentionAge      time.Duration
+	RetentionInterval time.Duration
+	InitialOffset     string
+	PollInterval      time.Duration
+}
+
+// NewConfig constructs a Config from the given connection map.
+// A retention size or age of zero disables the given retention.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Path:              values[PathKey],
+		Group:             values[GroupKey],
+		SegmentSize:       DefaultSegmentSize,
+		Sync:              values[SyncKey],
+		SyncInterval:      DefaultSyncInterval,
+		RetentionInterval: DefaultRetentionInterval,
+		InitialOffset:     values[InitialOffsetKey],
+		PollInterval:      DefaultPollInterval,
+	}
+
+	switch config.Sync {
+	case "":
+		config.Sync = SyncAlways
+	case SyncAlways, SyncInterval, SyncNever:
+	default:
+		return config, ErrInvalidSyncPolicy
+	}
+
+	switch config.InitialOffset {
+	case "":
+		config.InitialOffset = OffsetNewest
+	case OffsetNewest, OffsetOldest:
+	default:
+		return config, ErrInvalidInitialOffset
+	}
+
+	sizes := map[string]*int64{
+		SegmentSizeKey:   &config.SegmentSize,
+		RetentionSizeKey: &config.RetentionSize,
+	}
+
+	for key, size := range sizes {
+		if values[key] == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(values[key], 10, 64)
+		if err != nil {
+			return config, err
+		}
+
+		*size = value
+	}
+
+	durations := map[string]*time.Duration{
+		SyncIntervalKey:      &config.SyncInterval,
+		RetentionAgeKey:      &config.RetentionAge,
+		RetentionIntervalKey: &config.RetentionInterval,
+		PollIntervalKey:      &config.PollInterval,
+	}
+
+	for key, duration := range durations {
+		if values[key] == "" {
+			continue
+		}
+
+		value, err := time.ParseDuration(values[key])
+		if err != nil {
+			return config, err
+		}
+
+		*duration = value
+	}
+
+	return config, nil
+}
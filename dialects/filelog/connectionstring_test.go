@@ -0,0 +1,73 @@
+package filelog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("path=%s group=%s segment-size=%s sync=%s sync-interval=%s retention-size=%s retention-age=%s retention-interval=%s initial-offset=%s poll-interval=%s", val, val, val, val, val, val, val, val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		PathKey,
+		GroupKey,
+		SegmentSizeKey,
+		SyncKey,
+		SyncIntervalKey,
+		RetentionSizeKey,
+		RetentionAgeKey,
+		RetentionIntervalKey,
+		InitialOffsetKey,
+		PollIntervalKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		PathKey:          "/var/lib/commander",
+		GroupKey:         "group",
+		SyncKey:          SyncInterval,
+		RetentionSizeKey: "1024",
+		RetentionAgeKey:  "24h",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.SegmentSize != DefaultSegmentSize {
+		t.Fatal("Default segment size not set")
+	}
+
+	if conf.Sync != SyncInterval || conf.SyncInterval != DefaultSyncInterval {
+		t.Fatal("Unexpected sync policy")
+	}
+
+	if conf.RetentionSize != 1024 || conf.RetentionAge != 24*time.Hour {
+		t.Fatal("Unexpected retention")
+	}
+
+	if conf.InitialOffset != OffsetNewest {
+		t.Fatal("Default initial offset not set")
+	}
+}
+
+// TestNewConfigInvalidSync tests if a error is returned for a invalid sync policy
+func TestNewConfigInvalidSync(t *testing.T) {
+	_, err := NewConfig(ConnectionMap{SyncKey: "sometimes"})
+	if err != ErrInvalidSyncPolicy {
+		t.Fatal("unexpected error")
+	}
+}
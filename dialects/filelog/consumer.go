@@ -0,0 +1,270 @@
+package filelog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Topic represents a thread safe list of subscriptions
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	notify        chan struct{}
+	mutex         sync.RWMutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+		notify:        make(chan struct{}, 1),
+	}
+}
+
+// NewConsumer constructs a new file log consumer
+func NewConsumer(store *Store, config Config) *Consumer {
+	return &Consumer{
+		store:  store,
+		config: config,
+		topics: make(map[string]*Topic),
+	}
+}
+
+// Consumer reads the topic logs from the committed consumer group offsets.
+// Records are delivered in order, the offset of the consumer group is committed once
+// a message is acknowledged by all subscriptions. Negative acknowledged messages are
+// redelivered once the poll interval is reached.
+type Consumer struct {
+	store  *Store
+	config Config
+	topics map[string]*Topic
+	cancel context.CancelFunc
+	loops  sync.WaitGroup
+	mutex  sync.RWMutex
+}
+
+// Connect starts reading the given topics from the committed consumer group offsets.
+// If no offset has been committed is the configured initial offset used.
+func (consumer *Consumer) Connect(topics ...types.Topic) error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.cancel = cancel
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		log, err := consumer.store.Log(topic.Name())
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		offset, committed, err := consumer.store.Committed(consumer.config.Group, topic.Name())
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		if !committed && consumer.config.InitialOffset == OffsetOldest {
+			offset = log.Oldest()
+		}
+
+		if !committed && consumer.config.InitialOffset == OffsetNewest {
+			offset = log.Newest()
+		}
+
+		// NOTE: the initial offset is committed to make sure that records appended
+		// before the first record is claimed are not skipped after a restart.
+		if !committed {
+			err = consumer.store.Commit(consumer.config.Group, topic.Name(), offset)
+			if err != nil {
+				cancel()
+				return err
+			}
+		}
+
+		consumer.loops.Add(1)
+		go consumer.Read(ctx, consumer.topics[topic.Name()], log, offset)
+	}
+
+	return nil
+}
+
+// Read reads and claims the records of the given log starting at the given offset till the
+// given context is done. Once all records are read is the consumer awaiting till the poll
+// interval is reached or when the consumer is notified about a newly appended record.
+// Records that could not be read, for example corrupt records, are logged and skipped.
+func (consumer *Consumer) Read(ctx context.Context, topic *Topic, topicLog *Log, offset int64) {
+	defer consumer.loops.Done()
+
+	for ctx.Err() == nil {
+		record, err := topicLog.Read(offset)
+		if err == ErrOffsetDeleted {
+			offset = topicLog.Oldest()
+			continue
+		}
+
+		if err == nil && consumer.Claim(ctx, topic, record) {
+			offset = record.Offset + 1
+			continue
+		}
+
+		if err != nil && err != ErrOffsetNotFound {
+			log.Errorf("skipping unreadable record at offset %d of topic %s: %s", offset, topic.topic.Name(), err)
+
+			if consumer.Commit(topic, offset+1) {
+				offset++
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-topic.notify:
+		case <-time.After(consumer.config.PollInterval):
+		}
+	}
+}
+
+// Claim emit's the given record as message to the subscriptions of the given topic. All subscriptions are
+// awaited untill done. The offset is committed once all subscriptions acknowledged the message.
+// Records are not claimed while the topic has no subscriptions, the reader is notified once a subscription is made.
+// A boolean is returned representing if the message has been committed.
+func (consumer *Consumer) Claim(ctx context.Context, topic *Topic, record Record) bool {
+	message := record.Message(topic.topic)
+
+	topic.mutex.RLock()
+	if len(topic.subscriptions) == 0 {
+		topic.mutex.RUnlock()
+		return false
+	}
+
+	for _, subscription := range topic.subscriptions {
+		message.Reset()
+
+		select {
+		case subscription.messages <- message:
+		case <-ctx.Done():
+			topic.mutex.RUnlock()
+			return false
+		}
+
+		err := message.Finally()
+		if err != nil {
+			topic.mutex.RUnlock()
+			return false
+		}
+	}
+	topic.mutex.RUnlock()
+
+	return consumer.Commit(topic, record.Offset+1)
+}
+
+// Commit commits the given offset as the next offset to be read by the consumer group.
+// A boolean is returned representing if the offset has been committed.
+func (consumer *Consumer) Commit(topic *Topic, offset int64) bool {
+	err := consumer.store.Commit(consumer.config.Group, topic.topic.Name(), offset)
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// Notify notifies the reader of the given topic that a new record has been appended
+func (consumer *Consumer) Notify(topic string) {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	collection, has := consumer.topics[topic]
+	if !has {
+		return
+	}
+
+	select {
+	case collection.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		collection := consumer.topics[topic.Name()]
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+
+		select {
+		case collection.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Close stops reading the topic logs and awaits till all claimed messages are processed
+func (consumer *Consumer) Close() error {
+	if consumer.cancel != nil {
+		consumer.cancel()
+	}
+
+	consumer.loops.Wait()
+	return nil
+}
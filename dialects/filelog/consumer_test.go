@@ -0,0 +1,284 @@
+package filelog
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Publish publishes the given amount of messages to the given topic
+func Publish(t *testing.T, dialect *Dialect, topic types.Topic, amount int) []*types.Message {
+	messages := make([]*types.Message, amount)
+
+	for i := 0; i < amount; i++ {
+		message := types.NewMessage("event", 1, nil, nil)
+		message.Topic = topic
+
+		err := dialect.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		messages[i] = message
+	}
+
+	return messages
+}
+
+// TestConsumerConsumption tests if produced messages are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, t.TempDir(), "group=mock poll-interval=1h", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+		t.Fatal("message key or data not preserved")
+	}
+
+	if !consumed.EOS || consumed.Status != types.StatusNotFound {
+		t.Fatal("message eos or status not preserved")
+	}
+
+	id, has := metadata.ParentIDFromContext(consumed.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+
+	position, has := PositionFromContext(consumed.Ctx())
+	if !has || position.Topic != topic.Name() || position.Offset != 0 {
+		t.Fatal("message position not set")
+	}
+}
+
+// TestConsumerNackRedelivery tests if negative acknowledged messages are redelivered in order
+func TestConsumerNackRedelivery(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, t.TempDir(), "group=mock", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, dialect, topic, 2)
+	testutil.Await(t, messages).Nack()
+
+	for _, expected := range published {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("messages not redelivered in order")
+		}
+
+		consumed.Ack()
+	}
+}
+
+// TestConsumerRestart tests if messages and committed offsets survive a dialect restart
+func TestConsumerRestart(t *testing.T) {
+	path := t.TempDir()
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	first := NewTestDialect(t, path, "group=mock", topic)
+	messages, err := first.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, first, topic, 3)
+	testutil.Await(t, messages).Ack()
+	testutil.Await(t, messages).Nack()
+
+	err = first.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewTestDialect(t, path, "group=mock", topic)
+	messages, err = second.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range published[1:] {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("consumer group did not resume from its committed offset")
+		}
+
+		consumed.Ack()
+	}
+}
+
+// TestConsumerRestartLateHandler tests if records retained before a restart are delivered
+// to a handler registered after the client has opened the dialect
+func TestConsumerRestartLateHandler(t *testing.T) {
+	path := t.TempDir()
+
+	open := func() (*commander.Group, *commander.Client) {
+		dialect, err := NewDialect("path=" + path + " poll-interval=10ms group=mock")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		group := commander.NewGroup(
+			commander.NewTopic("events", dialect, commander.EventMessage, commander.DefaultMode),
+		)
+
+		client, err := commander.NewClient(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return group, client
+	}
+
+	group, client := open()
+	for i := 0; i < 2; i++ {
+		err := group.ProduceEvent(types.NewMessage("event", 1, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	client.Close()
+
+	group, client = open()
+	defer client.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	consumed := make(chan *types.Message, 2)
+	group.HandleFunc(commander.EventMessage, "event", func(message *types.Message, writer commander.Writer) {
+		consumed <- message
+	})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-consumed:
+		case <-time.After(time.Second):
+			t.Fatalf("retained record %d not delivered to the handler", i)
+		}
+	}
+}
+
+// TestConsumerInitialOffset tests if a new consumer group starts at the configured initial offset
+func TestConsumerInitialOffset(t *testing.T) {
+	tests := map[string]struct {
+		offset   string
+		expected int
+	}{
+		"newest": {OffsetNewest, 1},
+		"oldest": {OffsetOldest, 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := t.TempDir()
+			topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+			dialect := NewTestDialect(t, path, "group=mock initial-offset="+test.offset, topic)
+			Publish(t, dialect, topic, 2)
+
+			err := dialect.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dialect = NewTestDialect(t, path, "group=other initial-offset="+test.offset, topic)
+			messages, err := dialect.Consumer().Subscribe(topic)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			Publish(t, dialect, topic, 1)
+
+			for i := 0; i < test.expected; i++ {
+				testutil.Await(t, messages).Ack()
+			}
+
+			select {
+			case <-messages:
+				t.Fatal("unexpected message consumed")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
+
+// TestConsumerCorruptRecord tests if corrupt records are skipped and committed past
+func TestConsumerCorruptRecord(t *testing.T) {
+	path := t.TempDir()
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, path, "group=mock", topic)
+
+	published := Publish(t, dialect, topic, 3)
+
+	// Corrupt the payload of the second record inside the first segment
+	dir := filepath.Join(path, topic.Name())
+
+	index, err := os.ReadFile(SegmentPath(dir, 0, IndexExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.OpenFile(SegmentPath(dir, 0, LogExtension), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	position := int64(binary.BigEndian.Uint64(index[indexEntrySize : indexEntrySize*2]))
+	_, err = file.WriteAt([]byte("corrupt"), position+recordHeaderSize)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []*types.Message{published[0], published[2]} {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("corrupt record not skipped")
+		}
+
+		consumed.Ack()
+	}
+
+	testutil.Eventually(t, time.Second, func() bool {
+		offset, _, err := dialect.store.Committed("mock", topic.Name())
+		return err == nil && offset == 3
+	})
+}
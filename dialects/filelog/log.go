@@ -0,0 +1,237 @@
+package filelog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Custom error types
+var (
+	ErrOffsetDeleted  = errors.New("offset has been removed by the retention policy")
+	ErrOffsetNotFound = errors.New("offset has not been written yet")
+)
+
+// OpenLog opens or creates the topic log inside the given directory
+func OpenLog(dir string, config Config) (*Log, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+LogExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	bases := []int64{}
+	for _, file := range files {
+		base, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(file), LogExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		bases = append(bases, base)
+	}
+
+	if len(bases) == 0 {
+		bases = append(bases, 0)
+	}
+
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	log := &Log{
+		dir:    dir,
+		config: config,
+	}
+
+	for _, base := range bases {
+		segment, err := OpenSegment(dir, base)
+		if err != nil {
+			log.Close()
+			return nil, err
+		}
+
+		log.segments = append(log.segments, segment)
+	}
+
+	return log, nil
+}
+
+// Log represents a append-only topic log split up into segments.
+// A new segment is created once the active segment exceeds the configured segment size.
+type Log struct {
+	dir      string
+	config   Config
+	segments []*Segment
+	mutex    sync.RWMutex
+}
+
+// active returns the segment records are appended to
+func (log *Log) active() *Segment {
+	return log.segments[len(log.segments)-1]
+}
+
+// Append appends the given record to the log and returns the record offset.
+// The active segment is synced if the sync policy is set to always.
+func (log *Log) Append(record Record) (int64, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	active := log.active()
+	if active.Size() >= log.config.SegmentSize && active.Next() > active.Base() {
+		err := log.roll()
+		if err != nil {
+			return 0, err
+		}
+
+		active = log.active()
+	}
+
+	offset, err := active.Append(record)
+	if err != nil {
+		return 0, err
+	}
+
+	if log.config.Sync == SyncAlways {
+		err = active.Sync()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// roll syncs the active segment and creates a new active segment
+func (log *Log) roll() error {
+	active := log.active()
+
+	if log.config.Sync != SyncNever {
+		err := active.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	segment, err := OpenSegment(log.dir, active.Next())
+	if err != nil {
+		return err
+	}
+
+	log.segments = append(log.segments, segment)
+	return nil
+}
+
+// Read reads the record with the given offset. ErrOffsetDeleted is returned if the offset
+// has been removed by the retention policy, ErrOffsetNotFound if the offset has not been written yet.
+func (log *Log) Read(offset int64) (Record, error) {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	if offset < log.segments[0].Base() {
+		return Record{}, ErrOffsetDeleted
+	}
+
+	if offset >= log.active().Next() {
+		return Record{}, ErrOffsetNotFound
+	}
+
+	index := sort.Search(len(log.segments), func(i int) bool {
+		return log.segments[i].Base() > offset
+	})
+
+	return log.segments[index-1].Read(offset)
+}
+
+// Oldest returns the offset of the oldest record available inside the log
+func (log *Log) Oldest() int64 {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	return log.segments[0].Base()
+}
+
+// Newest returns the offset of the next record appended to the log
+func (log *Log) Newest() int64 {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	return log.active().Next()
+}
+
+// Size returns the size of all log segments in bytes
+func (log *Log) Size() int64 {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	size := int64(0)
+	for _, segment := range log.segments {
+		size += segment.Size()
+	}
+
+	return size
+}
+
+// Cleanup removes the oldest segments exceeding the configured retention size or age.
+// The active segment is never removed. The amount of removed segments is returned.
+func (log *Log) Cleanup() (int, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	size := int64(0)
+	for _, segment := range log.segments {
+		size += segment.Size()
+	}
+
+	removed := 0
+	for len(log.segments) > 1 {
+		segment := log.segments[0]
+
+		exceedsSize := log.config.RetentionSize > 0 && size > log.config.RetentionSize
+		exceedsAge := log.config.RetentionAge > 0 && time.Since(segment.Modified()) > log.config.RetentionAge
+
+		if !exceedsSize && !exceedsAge {
+			break
+		}
+
+		err := segment.Remove()
+		if err != nil {
+			return removed, err
+		}
+
+		size -= segment.Size()
+		log.segments = log.segments[1:]
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Sync commits the written records of the active segment to disk
+func (log *Log) Sync() error {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	return log.active().Sync()
+}
+
+// Close closes all log segments
+func (log *Log) Close() error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	for _, segment := range log.segments {
+		err := segment.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package filelog
+
+import "testing"
+
+// NewTestLog opens a new log inside the given directory with the given config
+func NewTestLog(t *testing.T, dir string, config Config) *Log {
+	if config.Sync == "" {
+		config.Sync = SyncNever
+	}
+
+	log, err := OpenLog(dir, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		log.Close()
+	})
+
+	return log
+}
+
+// Append appends the given amount of records to the given log
+func Append(t *testing.T, log *Log, amount int) {
+	for i := 0; i < amount; i++ {
+		_, err := log.Append(Record{Data: []byte("data")})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestLogRoll tests if new segments are created once the segment size is exceeded
+func TestLogRoll(t *testing.T) {
+	log := NewTestLog(t, t.TempDir(), Config{SegmentSize: 1})
+	Append(t, log, 5)
+
+	if len(log.segments) != 5 {
+		t.Fatalf("unexpected amount of segments: %d", len(log.segments))
+	}
+
+	for offset := int64(0); offset < 5; offset++ {
+		record, err := log.Read(offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if record.Offset != offset {
+			t.Fatalf("unexpected record offset: %d", record.Offset)
+		}
+	}
+
+	_, err := log.Read(5)
+	if err != ErrOffsetNotFound {
+		t.Fatal("unexpected error for a unwritten offset")
+	}
+}
+
+// TestLogReopen tests if a reopened log continues at the last written offset
+func TestLogReopen(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{SegmentSize: 100}
+
+	log := NewTestLog(t, dir, config)
+	Append(t, log, 5)
+	log.Close()
+
+	log = NewTestLog(t, dir, config)
+	if log.Oldest() != 0 || log.Newest() != 5 {
+		t.Fatalf("unexpected offsets: %d, %d", log.Oldest(), log.Newest())
+	}
+
+	Append(t, log, 1)
+
+	record, err := log.Read(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Offset != 5 {
+		t.Fatal("unexpected record offset")
+	}
+}
+
+// TestLogRetentionSize tests if the oldest segments are removed once the retention size is exceeded
+func TestLogRetentionSize(t *testing.T) {
+	log := NewTestLog(t, t.TempDir(), Config{SegmentSize: 1, RetentionSize: 1})
+	Append(t, log, 5)
+
+	removed, err := log.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 4 {
+		t.Fatalf("unexpected amount of removed segments: %d", removed)
+	}
+
+	if log.Oldest() != 4 {
+		t.Fatalf("unexpected oldest offset: %d", log.Oldest())
+	}
+
+	_, err = log.Read(0)
+	if err != ErrOffsetDeleted {
+		t.Fatal("unexpected error for a removed offset")
+	}
+}
+
+// TestLogRetentionAge tests if segments older than the retention age are removed
+func TestLogRetentionAge(t *testing.T) {
+	log := NewTestLog(t, t.TempDir(), Config{SegmentSize: 1, RetentionAge: 1})
+	Append(t, log, 3)
+
+	removed, err := log.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 2 {
+		t.Fatalf("unexpected amount of removed segments: %d", removed)
+	}
+}
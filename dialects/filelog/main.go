@@ -0,0 +1,100 @@
+package filelog
+
+import (
+	"os"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Dialect represents the file log dialect
+type Dialect struct {
+	Connection Config
+
+	store    *Store
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new file log dialect.
+// Every topic is written as append-only segment files inside the configured path,
+// consumer group offsets are stored next to the topic segments. Messages are persisted
+// across process restarts. A path is expected to be written by a single dialect at a time.
+func NewDialect(connectionstring string) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewStore(connection)
+	consumer := NewConsumer(store, connection)
+
+	dialect := &Dialect{
+		Connection: connection,
+		store:      store,
+		consumer:   consumer,
+		producer:   NewProducer(store, consumer.Notify),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open creates the configured path if it does not exist and starts consuming the given topics
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	err := os.MkdirAll(dialect.Connection.Path, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = dialect.consumer.Connect(topics...)
+	if err != nil {
+		return err
+	}
+
+	dialect.store.Start()
+	return nil
+}
+
+// Cleanup removes the segments exceeding the configured retention size or age.
+// The amount of removed segments is returned.
+func (dialect *Dialect) Cleanup() (int, error) {
+	return dialect.store.Cleanup()
+}
+
+// Close closes the file log consumers, producers and topic logs
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	return dialect.store.Close()
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	_, err := os.Stat(dialect.Connection.Path)
+	return err == nil
+}
@@ -0,0 +1,66 @@
+package filelog
+
+import (
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestDialect constructs and opens a new file log dialect for the given path, connectionstring options and topics
+func NewTestDialect(t *testing.T, path string, options string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect("path=" + path + " poll-interval=50ms " + options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new file log dialect
+func TestNewDialect(t *testing.T) {
+	dialect := NewTestDialect(t, t.TempDir(), "group=mock")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNewDialectNoPath tests if a error is returned when no path is defined
+func TestNewDialectNoPath(t *testing.T) {
+	_, err := NewDialect("group=mock")
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
+
+// TestDialectCleanup tests if the dialect removes segments exceeding the retention size
+func TestDialectCleanup(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+	dialect := NewTestDialect(t, t.TempDir(), "group=mock segment-size=1 retention-size=1 retention-interval=1h", topic)
+
+	for i := 0; i < 3; i++ {
+		message := types.NewMessage("event", 1, nil, nil)
+		message.Topic = topic
+
+		err := dialect.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := dialect.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 2 {
+		t.Fatalf("unexpected amount of removed segments: %d", removed)
+	}
+}
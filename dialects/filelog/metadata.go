@@ -0,0 +1,33 @@
+package filelog
+
+import "context"
+
+// CtxKey typed context key
+type CtxKey string
+
+func (k CtxKey) String() string {
+	return string(k)
+}
+
+const (
+	// CtxPosition represents the log position context type
+	CtxPosition = CtxKey("filelog-position")
+)
+
+// Position log position metadata
+type Position struct {
+	Topic  string
+	Offset int64
+}
+
+// NewPositionContext creates a new context with the given log position attached.
+// NewPositionContext will overwrite any previously-appended metadata.
+func NewPositionContext(ctx context.Context, position Position) context.Context {
+	return context.WithValue(ctx, CtxPosition, position)
+}
+
+// PositionFromContext returns the log position in ctx if it exists
+func PositionFromContext(ctx context.Context) (position Position, ok bool) {
+	position, ok = ctx.Value(CtxPosition).(Position)
+	return
+}
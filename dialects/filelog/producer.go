@@ -0,0 +1,51 @@
+package filelog
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewProducer constructs a new file log producer. The given notify function is called
+// with the topic name once a message has been appended.
+func NewProducer(store *Store, notify func(topic string)) *Producer {
+	return &Producer{
+		store:  store,
+		notify: notify,
+	}
+}
+
+// Producer appends messages to the topic logs
+type Producer struct {
+	store      *Store
+	notify     func(topic string)
+	production sync.WaitGroup
+}
+
+// Publish appends the given message to the log of the message topic and notifies the consumers of the same dialect
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	log, err := producer.store.Log(message.Topic.Name())
+	if err != nil {
+		return err
+	}
+
+	_, err = log.Append(RecordFromMessage(message))
+	if err != nil {
+		return err
+	}
+
+	if producer.notify != nil {
+		producer.notify(message.Topic.Name())
+	}
+
+	return nil
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
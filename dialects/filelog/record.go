@@ -0,0 +1,48 @@
+package filelog
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Record represents a single message appended to a topic log
+type Record struct {
+	Offset    int64             `json:"offset"`
+	Timestamp int64             `json:"timestamp"`
+	Key       []byte            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers"`
+	Data      []byte            `json:"data,omitempty"`
+}
+
+// RecordFromMessage constructs a new log record of the given message.
+// The record offset is assigned once the record is appended to a log.
+func RecordFromMessage(message *types.Message) Record {
+	return Record{
+		Timestamp: time.Now().UnixNano(),
+		Key:       message.Key,
+		Headers:   headers.Marshal(message),
+		Data:      message.Data,
+	}
+}
+
+// Message constructs a commander message of the record for the given topic
+func (record Record) Message(topic types.Topic) *types.Message {
+	message := &types.Message{
+		Topic:     topic,
+		Key:       record.Key,
+		Data:      record.Data,
+		Status:    types.StatusOK,
+		Timestamp: time.Unix(0, record.Timestamp),
+	}
+
+	message.NewCtx(NewPositionContext(context.Background(), Position{
+		Topic:  topic.Name(),
+		Offset: record.Offset,
+	}))
+
+	headers.Unmarshal(message, record.Headers)
+	return message
+}
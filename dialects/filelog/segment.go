@@ -0,0 +1,279 @@
+package filelog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Segment file extensions
+const (
+	LogExtension   = ".log"
+	IndexExtension = ".index"
+)
+
+const (
+	// recordHeaderSize represents the size of a record header containing the payload length and checksum
+	recordHeaderSize = 8
+	// indexEntrySize represents the size of a single index entry containing the record position
+	indexEntrySize = 8
+)
+
+// Custom error types
+var (
+	ErrCorruptRecord = errors.New("corrupt record, checksum mismatch")
+)
+
+// SegmentPath returns the path of the segment file with the given base offset and extension
+func SegmentPath(dir string, base int64, extension string) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, extension))
+}
+
+// OpenSegment opens or creates the segment with the given base offset inside the given directory.
+// Records that have been partially written, for example during a crash, are truncated.
+func OpenSegment(dir string, base int64) (*Segment, error) {
+	log, err := os.OpenFile(SegmentPath(dir, base, LogExtension), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := os.OpenFile(SegmentPath(dir, base, IndexExtension), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	segment := &Segment{
+		base:  base,
+		log:   log,
+		index: index,
+	}
+
+	err = segment.recover()
+	if err != nil {
+		segment.Close()
+		return nil, err
+	}
+
+	return segment, nil
+}
+
+// Segment represents a append-only log file and its index. The log file contains the
+// records prefixed with their length and checksum. The index contains the position of
+// every record inside the log file, the record offset is the segment base offset
+// incremented by the index entry number.
+type Segment struct {
+	base     int64
+	next     int64
+	size     int64
+	modified time.Time
+	log      *os.File
+	index    *os.File
+}
+
+// recover restores the segment state of the log and index files.
+// Index entries pointing to incomplete records and trailing bytes are truncated.
+func (segment *Segment) recover() error {
+	stat, err := segment.log.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := stat.Size()
+	segment.modified = stat.ModTime()
+
+	stat, err = segment.index.Stat()
+	if err != nil {
+		return err
+	}
+
+	entries := stat.Size() / indexEntrySize
+	end := int64(0)
+
+	for ; entries > 0; entries-- {
+		position, err := segment.position(entries - 1)
+		if err != nil {
+			return err
+		}
+
+		length, err := segment.length(position, size)
+		if err != nil {
+			continue
+		}
+
+		end = position + recordHeaderSize + length
+		break
+	}
+
+	err = segment.index.Truncate(entries * indexEntrySize)
+	if err != nil {
+		return err
+	}
+
+	err = segment.log.Truncate(end)
+	if err != nil {
+		return err
+	}
+
+	segment.next = segment.base + entries
+	segment.size = end
+
+	return nil
+}
+
+// position returns the log file position of the given index entry
+func (segment *Segment) position(entry int64) (int64, error) {
+	bb := make([]byte, indexEntrySize)
+	_, err := segment.index.ReadAt(bb, entry*indexEntrySize)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(binary.BigEndian.Uint64(bb)), nil
+}
+
+// length validates the record at the given position and returns its payload length.
+// A error is returned if the record exceeds the given log size or is corrupt.
+func (segment *Segment) length(position int64, size int64) (int64, error) {
+	header := make([]byte, recordHeaderSize)
+	_, err := segment.log.ReadAt(header, position)
+	if err != nil {
+		return 0, err
+	}
+
+	length := int64(binary.BigEndian.Uint32(header[0:4]))
+	if position+recordHeaderSize+length > size {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	payload := make([]byte, length)
+	_, err = segment.log.ReadAt(payload, position+recordHeaderSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return 0, ErrCorruptRecord
+	}
+
+	return length, nil
+}
+
+// Append appends the given record to the segment. The record offset is set to the next segment offset.
+func (segment *Segment) Append(record Record) (int64, error) {
+	record.Offset = segment.next
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return 0, err
+	}
+
+	bb := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(bb[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(bb[4:8], crc32.ChecksumIEEE(payload))
+	copy(bb[recordHeaderSize:], payload)
+
+	_, err = segment.log.WriteAt(bb, segment.size)
+	if err != nil {
+		return 0, err
+	}
+
+	entry := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(entry, uint64(segment.size))
+
+	_, err = segment.index.WriteAt(entry, (segment.next-segment.base)*indexEntrySize)
+	if err != nil {
+		return 0, err
+	}
+
+	segment.size += int64(len(bb))
+	segment.next++
+	segment.modified = time.Now()
+
+	return record.Offset, nil
+}
+
+// Read reads the record with the given offset from the segment
+func (segment *Segment) Read(offset int64) (Record, error) {
+	record := Record{}
+
+	position, err := segment.position(offset - segment.base)
+	if err != nil {
+		return record, err
+	}
+
+	length, err := segment.length(position, segment.size)
+	if err != nil {
+		return record, err
+	}
+
+	payload := make([]byte, length)
+	_, err = segment.log.ReadAt(payload, position+recordHeaderSize)
+	if err != nil {
+		return record, err
+	}
+
+	err = json.Unmarshal(payload, &record)
+	return record, err
+}
+
+// Base returns the offset of the first record inside the segment
+func (segment *Segment) Base() int64 {
+	return segment.base
+}
+
+// Next returns the offset of the next record appended to the segment
+func (segment *Segment) Next() int64 {
+	return segment.next
+}
+
+// Size returns the size of the segment log file in bytes
+func (segment *Segment) Size() int64 {
+	return segment.size
+}
+
+// Modified returns the time the last record was appended to the segment
+func (segment *Segment) Modified() time.Time {
+	return segment.modified
+}
+
+// Sync commits the written log and index records to disk
+func (segment *Segment) Sync() error {
+	err := segment.log.Sync()
+	if err != nil {
+		return err
+	}
+
+	return segment.index.Sync()
+}
+
+// Close closes the segment log and index files
+func (segment *Segment) Close() error {
+	err := segment.log.Close()
+	if err != nil {
+		return err
+	}
+
+	return segment.index.Close()
+}
+
+// Remove closes and removes the segment log and index files
+func (segment *Segment) Remove() error {
+	err := segment.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(segment.log.Name())
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(segment.index.Name())
+}
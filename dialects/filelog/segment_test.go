@@ -0,0 +1,87 @@
+package filelog
+
+import (
+	"os"
+	"testing"
+)
+
+// TestSegmentAppendRead tests if appended records could be read by their offset
+func TestSegmentAppendRead(t *testing.T) {
+	segment, err := OpenSegment(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer segment.Close()
+
+	for i := 0; i < 3; i++ {
+		offset, err := segment.Append(Record{Data: []byte{byte(i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if offset != int64(10+i) {
+			t.Fatalf("unexpected offset: %d", offset)
+		}
+	}
+
+	record, err := segment.Read(11)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Offset != 11 || record.Data[0] != 1 {
+		t.Fatal("unexpected record read")
+	}
+}
+
+// TestSegmentRecover tests if partially written records are truncated once a segment is reopened
+func TestSegmentRecover(t *testing.T) {
+	dir := t.TempDir()
+
+	segment, err := OpenSegment(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = segment.Append(Record{Data: []byte("data")})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size := segment.Size()
+	segment.Close()
+
+	// Simulate a crash while writing the last record
+	err = os.Truncate(SegmentPath(dir, 0, LogExtension), size-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segment, err = OpenSegment(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer segment.Close()
+
+	if segment.Next() != 1 {
+		t.Fatalf("unexpected next offset: %d", segment.Next())
+	}
+
+	_, err = segment.Read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset, err := segment.Append(Record{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if offset != 1 {
+		t.Fatalf("unexpected offset: %d", offset)
+	}
+}
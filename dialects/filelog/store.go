@@ -0,0 +1,227 @@
+package filelog
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// OffsetExtension represents the file extension of consumer group offset files
+const OffsetExtension = ".offset"
+
+// NewStore constructs a new store for the configured path
+func NewStore(config Config) *Store {
+	return &Store{
+		config: config,
+		logs:   make(map[string]*Log),
+	}
+}
+
+// Store manages the topic logs and consumer group offsets stored inside the configured path.
+// Every topic is stored inside its own directory containing the log segments and the
+// committed offsets of the consumer groups.
+type Store struct {
+	config Config
+	logs   map[string]*Log
+	cancel context.CancelFunc
+	jobs   sync.WaitGroup
+	mutex  sync.Mutex
+}
+
+// dir returns the directory of the given topic
+func (store *Store) dir(topic string) string {
+	return filepath.Join(store.config.Path, url.PathEscape(topic))
+}
+
+// Log returns the log of the given topic. The log is opened if it has not been opened before.
+func (store *Store) Log(topic string) (*Log, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.logs[topic] != nil {
+		return store.logs[topic], nil
+	}
+
+	log, err := OpenLog(store.dir(topic), store.config)
+	if err != nil {
+		return nil, err
+	}
+
+	store.logs[topic] = log
+	return log, nil
+}
+
+// Committed returns the committed offset of the given consumer group and topic.
+// A boolean is returned representing if a offset has been committed.
+func (store *Store) Committed(group string, topic string) (int64, bool, error) {
+	bb, err := os.ReadFile(filepath.Join(store.dir(topic), url.PathEscape(group)+OffsetExtension))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(bb)), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return offset, true, nil
+}
+
+// Commit commits the given offset for the given consumer group and topic.
+// The offset file is replaced atomically to prevent partially written offsets.
+func (store *Store) Commit(group string, topic string, offset int64) error {
+	path := filepath.Join(store.dir(topic), url.PathEscape(group)+OffsetExtension)
+
+	file, err := os.CreateTemp(store.dir(topic), url.PathEscape(group)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(strconv.FormatInt(offset, 10))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if store.config.Sync == SyncAlways {
+		err = file.Sync()
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// opened returns all opened topic logs
+func (store *Store) opened() []*Log {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	logs := make([]*Log, 0, len(store.logs))
+	for _, log := range store.logs {
+		logs = append(logs, log)
+	}
+
+	return logs
+}
+
+// Cleanup removes the segments of all opened topic logs exceeding the configured retention.
+// The amount of removed segments is returned.
+func (store *Store) Cleanup() (int, error) {
+	removed := 0
+
+	for _, log := range store.opened() {
+		count, err := log.Cleanup()
+		removed += count
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// Sync commits the written records of all opened topic logs to disk
+func (store *Store) Sync() error {
+	for _, log := range store.opened() {
+		err := log.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the background sync and retention jobs. The sync job is only started if the
+// sync policy is set to interval, the retention job only if a retention size or age is configured.
+func (store *Store) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	store.cancel = cancel
+
+	if store.config.Sync == SyncInterval {
+		store.schedule(ctx, store.config.SyncInterval, store.Sync)
+	}
+
+	if store.config.RetentionSize > 0 || store.config.RetentionAge > 0 {
+		store.schedule(ctx, store.config.RetentionInterval, func() error {
+			_, err := store.Cleanup()
+			return err
+		})
+	}
+}
+
+// schedule calls the given job every interval till the given context is done
+func (store *Store) schedule(ctx context.Context, interval time.Duration, job func() error) {
+	store.jobs.Add(1)
+
+	go func() {
+		defer store.jobs.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := job()
+			if err != nil {
+				log.Error(err)
+			}
+		}
+	}()
+}
+
+// Close stops the background jobs, syncs and closes all opened topic logs
+func (store *Store) Close() error {
+	if store.cancel != nil {
+		store.cancel()
+	}
+
+	store.jobs.Wait()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for topic, log := range store.logs {
+		if store.config.Sync != SyncNever {
+			err := log.Sync()
+			if err != nil {
+				return err
+			}
+		}
+
+		err := log.Close()
+		if err != nil {
+			return err
+		}
+
+		delete(store.logs, topic)
+	}
+
+	return nil
+}
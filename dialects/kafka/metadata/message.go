@@ -29,7 +29,7 @@ func MessageFromMessage(consumed *sarama.ConsumerMessage) *commander.Message {
 	}
 
 	message.NewCtx(ctx)
-	headers := map[string][]string{}
+	headers := metadata.Header{}
 
 headers:
 	for _, record := range consumed.Headers {
@@ -79,6 +79,10 @@ headers:
 		}
 	}
 
+	if len(headers) > 0 {
+		message.NewCtx(metadata.AppendToHeaderContext(message.Ctx(), headers))
+	}
+
 	return message
 }
 
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestMessageHeaders tests if the message metadata and custom headers are preserved
+// when converting a commander message to a sarama message and back.
+func TestMessageHeaders(t *testing.T) {
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), "application/json"))
+
+	produced := MessageToMessage(message)
+	consumed := &sarama.ConsumerMessage{
+		Topic: produced.Topic,
+		Key:   []byte("key"),
+		Value: []byte("data"),
+	}
+
+	for index := range produced.Headers {
+		consumed.Headers = append(consumed.Headers, &produced.Headers[index])
+	}
+
+	result := MessageFromMessage(consumed)
+
+	if result.ID != message.ID || result.Action != "event" || result.Version != 2 {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	id, has := metadata.ParentIDFromContext(result.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+
+	contentType, _ := metadata.ContentTypeFromContext(result.Ctx())
+	if contentType != "application/json" {
+		t.Fatalf("content type not preserved: %s", contentType)
+	}
+}
@@ -3,6 +3,7 @@ package mock
 import (
 	"sync"
 
+	"github.com/jeroenrinzema/commander/internal/metadata"
 	"github.com/jeroenrinzema/commander/internal/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -11,47 +12,127 @@ import (
 type Consumer struct {
 	subscriptions map[string]*SubscriptionCollection
 	workers       int8
-	consumptions  sync.WaitGroup
+	retention     bool
+	retentionSize int
+	initialOffset int64
+	inflight      InFlight
+	delivering    map[string]int
 	mutex         sync.RWMutex
 	logger        *log.Logger
 }
 
-// Emit emits the given message to the subscribed consumers
+// Emit emits the given message to the subscribed consumers.
+// This method blocks till all subscriptions have resolved the message.
 func (consumer *Consumer) Emit(message *types.Message) {
+	consumer.inflight.Add()
+	consumer.emit(message)
+}
+
+// emit emits the given message to the subscribed consumers.
+// The message is expected to be marked as in-flight before emit is called.
+func (consumer *Consumer) emit(message *types.Message) {
 	consumer.logger.Debug("emitting message!")
+	defer consumer.inflight.Done()
 
 	consumer.mutex.Lock()
-	consumer.consumptions.Add(1)
+	collection := consumer.topic(message.Topic.Name())
+	consumer.mutex.Unlock()
 
-	defer consumer.consumptions.Done()
+	consumer.deliver(message)
+	collection.Emit(message)
+	consumer.delivered(message)
+}
 
-	collection, has := consumer.subscriptions[message.Topic.Name()]
-	if !has {
-		consumer.mutex.Unlock()
-		return
+// Redeliver redelivers the messages negative acknowledged by all members of a consumer group in the background.
+// The redelivered messages are marked as in-flight.
+func (consumer *Consumer) Redeliver() {
+	consumer.mutex.RLock()
+	collections := make([]*SubscriptionCollection, 0, len(consumer.subscriptions))
+	for _, collection := range consumer.subscriptions {
+		collections = append(collections, collection)
+	}
+	consumer.mutex.RUnlock()
+
+	consumer.redeliver(collections...)
+}
+
+// redeliver redelivers the messages kept by the consumer groups of the given collections in the background
+func (consumer *Consumer) redeliver(collections ...*SubscriptionCollection) {
+	for _, collection := range collections {
+		pending := collection.Pending()
+		if len(pending) == 0 {
+			continue
+		}
+
+		consumer.inflight.Add()
+
+		go func(collection *SubscriptionCollection) {
+			defer consumer.inflight.Done()
+
+			consumer.deliver(pending...)
+			collection.Redeliver()
+			consumer.delivered(pending...)
+		}(collection)
 	}
+}
+
+// deliver marks the given messages as being delivered
+func (consumer *Consumer) deliver(messages ...*types.Message) {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
 
-	length := len(collection.list)
-	if length == 0 {
-		consumer.mutex.Unlock()
-		return
+	for _, message := range messages {
+		consumer.delivering[message.ID]++
 	}
+}
 
-	resolved := make(chan struct{}, 0)
+// delivered marks the given messages as no longer being delivered
+func (consumer *Consumer) delivered(messages ...*types.Message) {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
 
-	go func(collection *SubscriptionCollection, message *types.Message) {
-		collection.mutex.Lock()
-		for _, subscription := range collection.list {
-			message.Reset()
-			subscription.messages <- message
-			message.Finally()
+	for _, message := range messages {
+		consumer.delivering[message.ID]--
+		if consumer.delivering[message.ID] == 0 {
+			delete(consumer.delivering, message.ID)
 		}
-		collection.mutex.Unlock()
-		close(resolved)
-	}(collection, message)
+	}
+}
 
-	consumer.mutex.Unlock()
-	<-resolved
+// reentrant checks if the given message is published by a handler while its parent message is being delivered
+// and the topic of the given message is emitting. Emitting the message inline would block till the topic has
+// finished emitting, which in turn awaits the handler that is publishing the message.
+func (consumer *Consumer) reentrant(message *types.Message) bool {
+	parent, has := metadata.ParentIDFromContext(message.Ctx())
+	if !has {
+		return false
+	}
+
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	if consumer.delivering[string(parent)] == 0 {
+		return false
+	}
+
+	collection, has := consumer.subscriptions[message.Topic.Name()]
+	return has && collection.Emitting()
+}
+
+// topic returns the subscription collection of the given topic.
+// A new collection is constructed if no collection exists for the given topic.
+// The consumer mutex is expected to be locked before topic is called.
+func (consumer *Consumer) topic(name string) *SubscriptionCollection {
+	collection, has := consumer.subscriptions[name]
+	if !has {
+		collection = NewTopic()
+		collection.retain = consumer.retention
+		collection.size = consumer.retentionSize
+		collection.inflight = &consumer.inflight
+		consumer.subscriptions[name] = collection
+	}
+
+	return collection
 }
 
 // Subscribe creates a new topic subscription that will receive
@@ -59,23 +140,48 @@ func (consumer *Consumer) Emit(message *types.Message) {
 // will return a message channel and a close function.
 // Once a message is consumed should the marked channel be called. Pass a nil for a successful consume and
 // a error if a error occurred during processing.
+// The subscription starts consuming from the initial offset configured for the dialect.
 func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
-	subscription := &Subscription{
-		messages: make(chan *types.Message, 0),
-	}
+	return consumer.subscribe("", consumer.initialOffset, topics)
+}
 
-	consumer.mutex.Lock()
-	defer consumer.mutex.Unlock()
+// SubscribeGroup creates a new topic subscription that joins the given consumer group.
+// Messages are delivered to a single member of every consumer group. A message that is
+// negative acknowledged is redelivered to another member of the group. Messages negative
+// acknowledged by all members are redelivered once the subscription joins the group.
+// Subscriptions without a consumer group receive all messages.
+func (consumer *Consumer) SubscribeGroup(group string, topics ...types.Topic) (<-chan *types.Message, error) {
+	return consumer.subscribe(group, consumer.initialOffset, topics)
+}
+
+// SubscribeOffset creates a new topic subscription that starts consuming from the given offset.
+// The offset could be a absolute offset, OffsetOldest or OffsetNewest. Retained messages
+// starting from the given offset are replayed before new messages are received.
+func (consumer *Consumer) SubscribeOffset(offset int64, topics ...types.Topic) (<-chan *types.Message, error) {
+	return consumer.subscribe("", offset, topics)
+}
+
+func (consumer *Consumer) subscribe(group string, offset int64, topics []types.Topic) (<-chan *types.Message, error) {
+	subscription := NewSubscription(group)
 
+	collections := []*SubscriptionCollection{}
+
+	consumer.mutex.Lock()
 	for _, topic := range topics {
-		if consumer.subscriptions[topic.Name()] == nil {
-			consumer.subscriptions[topic.Name()] = NewTopic()
-		}
+		collections = append(collections, consumer.topic(topic.Name()))
+	}
+	consumer.mutex.Unlock()
+
+	for _, collection := range collections {
+		collection.Subscribe(subscription, offset)
+	}
 
-		consumer.subscriptions[topic.Name()].list[subscription.messages] = subscription
+	// NOTE: messages negative acknowledged by all members of the group are redelivered once a member joins
+	if group != "" {
+		consumer.redeliver(collections...)
 	}
 
-	consumer.logger.Debugf("subscribing to: %+v, %v", topics, subscription.messages)
+	consumer.logger.Debugf("subscribing to: %+v, %v, %s", topics, subscription.messages, group)
 	return subscription.messages, nil
 }
 
@@ -85,18 +191,23 @@ func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
 	consumer.logger.Debugf("unsubscribe: %v", sub)
 
 	consumer.mutex.Lock()
-	defer consumer.mutex.Unlock()
 
+	var subscription *Subscription
 	for _, collection := range consumer.subscriptions {
-		subscription, has := collection.list[sub]
-		if has {
-			collection.mutex.Lock()
-			delete(collection.list, sub)
-			close(subscription.messages)
-			collection.mutex.Unlock()
+		removed := collection.Remove(sub)
+		if removed != nil {
+			subscription = removed
 		}
 	}
 
+	consumer.mutex.Unlock()
+
+	// NOTE: the subscription is closed after unlocking the consumer since closing awaits
+	// the message being delivered, which could be publishing new messages.
+	if subscription != nil {
+		subscription.Close()
+	}
+
 	return nil
 }
 
@@ -104,11 +215,11 @@ func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
 func (consumer *Consumer) Close() error {
 	consumer.logger.Info("closing mock dialect consumer")
 
+	<-consumer.inflight.Idle()
+
 	consumer.mutex.Lock()
 	defer consumer.mutex.Unlock()
 
-	consumer.consumptions.Wait()
-
 	for topic := range consumer.subscriptions {
 		delete(consumer.subscriptions, topic)
 	}
@@ -2,6 +2,8 @@ package mock
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,3 +44,389 @@ func TestConsumerConsumption(t *testing.T) {
 	case <-sink:
 	}
 }
+
+// TestConsumerGroups tests if messages are delivered to a single member of every consumer group
+func TestConsumerGroups(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	var consumed sync.Map
+	subscribe := func(group string, name string) {
+		messages, err := dialect.Consumer().(*Consumer).SubscribeGroup(group, topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counter := new(int32)
+		consumed.Store(name, counter)
+
+		go func() {
+			for message := range messages {
+				atomic.AddInt32(counter, 1)
+				message.Ack()
+			}
+		}()
+	}
+
+	subscribe("a", "a1")
+	subscribe("a", "a2")
+	subscribe("b", "b1")
+	subscribe("", "broadcast")
+
+	amount := 4
+	for i := 0; i < amount; i++ {
+		message := types.NewMessage("testing", 1, nil, nil)
+		message.Topic = topic
+		dialect.Producer().Publish(message)
+	}
+
+	expected := map[string]int32{
+		"a1":        2,
+		"a2":        2,
+		"b1":        4,
+		"broadcast": 4,
+	}
+
+	for name, amount := range expected {
+		counter, _ := consumed.Load(name)
+		result := atomic.LoadInt32(counter.(*int32))
+		if result != amount {
+			t.Errorf("unexpected amount of messages consumed by %s: %d, expected %d", name, result, amount)
+		}
+	}
+}
+
+// TestConsumerGroupRedelivery tests if a negative acknowledged message is redelivered to another group member
+func TestConsumerGroupRedelivery(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	group := dialect.ConsumerGroup("group")
+	topic := types.NewTopic("mock", group, types.EventMessage, types.DefaultMode)
+
+	nacking, err := group.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acking, err := group.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := make(chan *types.Message, 1)
+
+	go func() {
+		for message := range nacking {
+			message.Nack()
+		}
+	}()
+
+	go func() {
+		for message := range acking {
+			delivered <- message
+			message.Ack()
+		}
+	}()
+
+	message := types.NewMessage("testing", 1, nil, nil)
+	message.Topic = topic
+	group.Producer().Publish(message)
+
+	select {
+	case <-delivered:
+	default:
+		t.Fatal("message was not redelivered to another group member")
+	}
+
+	err = group.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestConsumerGroupSingleMemberRedelivery tests if a message negative acknowledged by the only group member is redelivered
+func TestConsumerGroupSingleMemberRedelivery(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	group := dialect.ConsumerGroup("group")
+	topic := types.NewTopic("mock", group, types.EventMessage, types.DefaultMode)
+
+	messages, err := group.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := make(chan string, 3)
+
+	go func() {
+		attempts := 0
+		for message := range messages {
+			delivered <- message.Action
+
+			attempts++
+			if attempts == 1 {
+				message.Nack()
+				continue
+			}
+
+			message.Ack()
+		}
+	}()
+
+	for _, action := range []string{"first", "second"} {
+		message := types.NewMessage(action, 1, nil, nil)
+		message.Topic = topic
+		group.Producer().Publish(message)
+	}
+
+	expected := []string{"first", "first", "second"}
+	for _, action := range expected {
+		select {
+		case result := <-delivered:
+			if result != action {
+				t.Fatalf("unexpected delivered message %s, expected %s", result, action)
+			}
+		default:
+			t.Fatal("negative acknowledged message was not redelivered")
+		}
+	}
+}
+
+// TestConsumerGroupJoinRedelivery tests if a message negative acknowledged by all group members is redelivered once a member joins
+func TestConsumerGroupJoinRedelivery(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	failing, err := dialect.ConsumerGroup("group").Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for message := range failing {
+			message.Nack()
+		}
+	}()
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	joined, err := dialect.ConsumerGroup("group").Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case redelivered := <-joined:
+		redelivered.Ack()
+
+		if redelivered.ID != message.ID {
+			t.Fatal("unexpected message redelivered")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("negative acknowledged message not redelivered once a member joined")
+	}
+
+	dialect.Flush()
+}
+
+// TestConsumerGroupFlushRedelivery tests if a message negative acknowledged by all group members is redelivered when flushed
+func TestConsumerGroupFlushRedelivery(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	group := dialect.ConsumerGroup("group")
+	topic := types.NewTopic("mock", group, types.EventMessage, types.DefaultMode)
+
+	messages, err := group.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := int32(0)
+
+	go func() {
+		for message := range messages {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				message.Nack()
+				continue
+			}
+
+			message.Ack()
+		}
+	}()
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = group.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect.Flush()
+
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Fatalf("unexpected amount of delivery attempts: %d", attempts)
+	}
+}
+
+// Consume consumes the given amount of messages from the given subscription and returns their offsets
+func Consume(t *testing.T, messages <-chan *types.Message, amount int) []int64 {
+	offsets := []int64{}
+
+	for i := 0; i < amount; i++ {
+		select {
+		case message := <-messages:
+			offset, has := OffsetFromContext(message.Ctx())
+			if !has {
+				t.Fatal("message has no offset")
+			}
+
+			offsets = append(offsets, offset)
+			message.Ack()
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("timeout reached, consumed %d of %d messages", i, amount)
+		}
+	}
+
+	return offsets
+}
+
+// TestConsumerReplayInFlight tests if messages emitted while replaying are delivered in order after the
+// retained messages, and if the replay is tracked as in-flight without blocking unsubscribing
+func TestConsumerReplayInFlight(t *testing.T) {
+	dialect := NewDialect(WithRetention(0), WithInitialOffset(OffsetOldest))
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	publish := func() {
+		message := types.NewMessage("testing", 1, nil, nil)
+		message.Topic = topic
+		dialect.Producer().Publish(message)
+	}
+
+	for i := 0; i < 3; i++ {
+		publish()
+	}
+
+	dialect.Flush()
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets := Consume(t, messages, 1)
+	publish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if dialect.WaitIdle(ctx) == nil {
+		t.Fatal("replay not tracked as in-flight")
+	}
+
+	unsubscribed := make(chan struct{})
+	go func() {
+		dialect.Consumer().Unsubscribe(idle)
+		close(unsubscribed)
+	}()
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("unsubscribe blocked by the replay")
+	}
+
+	offsets = append(offsets, Consume(t, messages, 3)...)
+	for index, offset := range offsets {
+		if offset != int64(index) {
+			t.Fatalf("unexpected offsets %v", offsets)
+		}
+	}
+
+	dialect.Flush()
+}
+
+// TestConsumerReplay tests if retained messages are replayed from the given offset
+func TestConsumerReplay(t *testing.T) {
+	dialect := NewDialect(WithRetention(0), WithInitialOffset(OffsetOldest))
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	for i := 0; i < 5; i++ {
+		message := types.NewMessage("testing", 1, nil, nil)
+		message.Topic = topic
+		dialect.Producer().Publish(message)
+	}
+
+	dialect.Flush()
+
+	oldest, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets := Consume(t, oldest, 5)
+	for index, offset := range offsets {
+		if offset != int64(index) {
+			t.Fatalf("unexpected offset %d, expected %d", offset, index)
+		}
+	}
+
+	consumer := dialect.Consumer().(*Consumer)
+	dialect.Consumer().Unsubscribe(oldest)
+
+	offset, err := consumer.SubscribeOffset(3, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets = Consume(t, offset, 2)
+	if offsets[0] != 3 || offsets[1] != 4 {
+		t.Fatalf("unexpected offsets %v", offsets)
+	}
+
+	dialect.Consumer().Unsubscribe(offset)
+
+	newest, err := consumer.SubscribeOffset(OffsetNewest, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("testing", 1, nil, nil)
+	message.Topic = topic
+	dialect.Producer().Publish(message)
+
+	offsets = Consume(t, newest, 1)
+	if offsets[0] != 5 {
+		t.Fatalf("unexpected offset %d, expected the newest message", offsets[0])
+	}
+}
+
+// TestConsumerRetentionSize tests if the oldest messages are removed once the retention size is reached
+func TestConsumerRetentionSize(t *testing.T) {
+	dialect := NewDialect(WithRetention(2), WithSynchronousDelivery())
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	for i := 0; i < 5; i++ {
+		message := types.NewMessage("testing", 1, nil, nil)
+		message.Topic = topic
+		dialect.Producer().Publish(message)
+	}
+
+	messages, err := dialect.Consumer().(*Consumer).SubscribeOffset(OffsetOldest, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets := Consume(t, messages, 2)
+	if offsets[0] != 3 || offsets[1] != 4 {
+		t.Fatalf("unexpected offsets %v", offsets)
+	}
+}
@@ -0,0 +1,144 @@
+package mock
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+var (
+	// ErrInjectedFault is returned when a publish fault got injected
+	ErrInjectedFault = errors.New("injected publish fault")
+)
+
+// Faults represents the faults that are injected into the mock dialect.
+// Probabilities are represented as a value between 0 and 1. Faults are drawn from a
+// random source constructed with the given seed, identical seeds and publish sequences
+// result in identical faults.
+type Faults struct {
+	// Seed is used to construct the random source faults are drawn from
+	Seed int64
+	// PublishErrorRate is the probability that a publish returns a ErrInjectedFault
+	PublishErrorRate float64
+	// PublishErrorOn contains the publish calls (starting at 1) that return a ErrInjectedFault
+	PublishErrorOn []int
+	// Latency is the duration that is awaited before a message is delivered
+	Latency time.Duration
+	// DropRate is the probability that a published message is not delivered
+	DropRate float64
+	// DuplicateRate is the probability that a published message is delivered twice
+	DuplicateRate float64
+	// ReorderRate is the probability that a published message is held back and
+	// delivered after the next message published to the same topic
+	ReorderRate float64
+}
+
+// Apply applies the given faults to the given mock dialect options
+func (faults Faults) Apply(options *Options) {
+	options.Faults = &faults
+}
+
+// WithFaults returns a Option that injects the given faults into the mock dialect
+func WithFaults(faults Faults) Option {
+	return faults
+}
+
+// NewInjector constructs a new fault injector for the given faults
+func NewInjector(faults Faults) *Injector {
+	return &Injector{
+		faults: faults,
+		random: rand.New(rand.NewSource(faults.Seed)),
+		held:   make(map[string]*types.Message),
+	}
+}
+
+// Injector decides which faults are injected for published messages
+type Injector struct {
+	faults Faults
+	random *rand.Rand
+	calls  int
+	held   map[string]*types.Message
+	mutex  sync.Mutex
+}
+
+// Latency returns the duration that should be awaited before a message is delivered
+func (injector *Injector) Latency() time.Duration {
+	return injector.faults.Latency
+}
+
+// Publish marks a new publish call. A ErrInjectedFault is returned if the publish should fail.
+func (injector *Injector) Publish() error {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	injector.calls++
+
+	for _, call := range injector.faults.PublishErrorOn {
+		if call == injector.calls {
+			return ErrInjectedFault
+		}
+	}
+
+	if injector.draw(injector.faults.PublishErrorRate) {
+		return ErrInjectedFault
+	}
+
+	return nil
+}
+
+// Deliveries returns the messages that should be delivered in chronological order for the given published message.
+// Messages could be dropped, duplicated or held back till the next message is published to the same topic.
+func (injector *Injector) Deliveries(message *types.Message) []*types.Message {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	if injector.draw(injector.faults.DropRate) {
+		return nil
+	}
+
+	deliveries := []*types.Message{message}
+	if injector.draw(injector.faults.DuplicateRate) {
+		deliveries = append(deliveries, message)
+	}
+
+	topic := message.Topic.Name()
+	held, has := injector.held[topic]
+	if has {
+		delete(injector.held, topic)
+		return append(deliveries, held)
+	}
+
+	if injector.draw(injector.faults.ReorderRate) {
+		injector.held[topic] = message
+		return deliveries[1:]
+	}
+
+	return deliveries
+}
+
+// Release returns and releases all held back messages
+func (injector *Injector) Release() []*types.Message {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	released := []*types.Message{}
+	for topic, message := range injector.held {
+		released = append(released, message)
+		delete(injector.held, topic)
+	}
+
+	return released
+}
+
+// draw reports whether a fault with the given probability should be injected.
+// The random source is only consulted for non zero probabilities to keep fault sequences stable.
+func (injector *Injector) draw(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+
+	return injector.random.Float64() < probability
+}
@@ -0,0 +1,135 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewFaultyDialect constructs a new synchronous mock dialect with the given faults and a subscription on a "mock" topic.
+// The actions of all consumed messages are written to the returned channel.
+func NewFaultyDialect(t *testing.T, faults Faults) (*Dialect, types.Topic, <-chan string) {
+	dialect := NewDialect(WithSynchronousDelivery(), WithFaults(faults))
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := make(chan string, 100)
+	go func() {
+		for message := range messages {
+			actions <- message.Action
+			message.Ack()
+		}
+	}()
+
+	return dialect, topic, actions
+}
+
+// Publish publishes a new message with the given action to the given topic
+func Publish(dialect *Dialect, topic types.Topic, action string) error {
+	message := types.NewMessage(action, 1, nil, nil)
+	message.Topic = topic
+
+	return dialect.Producer().Publish(message)
+}
+
+// TestFaultsPublishErrorOn tests if publish errors are injected on the given calls
+func TestFaultsPublishErrorOn(t *testing.T) {
+	dialect, topic, actions := NewFaultyDialect(t, Faults{
+		PublishErrorOn: []int{2},
+	})
+
+	expected := []error{nil, ErrInjectedFault, nil}
+	for _, result := range expected {
+		err := Publish(dialect, topic, "testing")
+		if err != result {
+			t.Fatalf("unexpected result %v, expected %v", err, result)
+		}
+	}
+
+	dialect.Flush()
+
+	if len(actions) != 2 {
+		t.Fatalf("unexpected amount of deliveries %d", len(actions))
+	}
+}
+
+// TestFaultsPublishErrorSeed tests if identical seeds result in identical publish errors
+func TestFaultsPublishErrorSeed(t *testing.T) {
+	faults := Faults{
+		Seed:             42,
+		PublishErrorRate: 0.5,
+	}
+
+	first := NewInjector(faults)
+	second := NewInjector(faults)
+
+	for i := 0; i < 100; i++ {
+		if first.Publish() != second.Publish() {
+			t.Fatal("injected faults are not reproducible")
+		}
+	}
+}
+
+// TestFaultsDrop tests if published messages are dropped
+func TestFaultsDrop(t *testing.T) {
+	dialect, topic, actions := NewFaultyDialect(t, Faults{
+		DropRate: 1,
+	})
+
+	err := Publish(dialect, topic, "testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect.Flush()
+
+	if len(actions) != 0 {
+		t.Fatal("message was not dropped")
+	}
+}
+
+// TestFaultsDuplicate tests if published messages are duplicated
+func TestFaultsDuplicate(t *testing.T) {
+	dialect, topic, actions := NewFaultyDialect(t, Faults{
+		DuplicateRate: 1,
+	})
+
+	err := Publish(dialect, topic, "testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect.Flush()
+
+	if len(actions) != 2 {
+		t.Fatalf("unexpected amount of deliveries %d", len(actions))
+	}
+}
+
+// TestFaultsReorder tests if published messages are delivered out of order
+func TestFaultsReorder(t *testing.T) {
+	dialect, topic, actions := NewFaultyDialect(t, Faults{
+		ReorderRate: 1,
+	})
+
+	for _, action := range []string{"first", "second", "third"} {
+		err := Publish(dialect, topic, action)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dialect.Flush()
+
+	expected := []string{"second", "first", "third"}
+	for _, action := range expected {
+		result := <-actions
+		if result != action {
+			t.Fatalf("unexpected action %s, expected %s", result, action)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// GroupDialect represents a view of a mock dialect whose subscriptions join a consumer group.
+// Messages are shared with the dialect the view is constructed from.
+type GroupDialect struct {
+	dialect  *Dialect
+	consumer *GroupConsumer
+}
+
+// Open notifies a dialect to open the dialect.
+// No further topic assignments should be made.
+func (dialect *GroupDialect) Open([]types.Topic) error {
+	return nil
+}
+
+// Consumer returns the consumer group consumer
+func (dialect *GroupDialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the producer of the dialect the view is constructed from
+func (dialect *GroupDialect) Producer() types.Producer {
+	return dialect.dialect.Producer()
+}
+
+// Healthy when called should it check if the dialect's consumer/producer are healthy and
+// up and running. This method could be called to check if the service is up and running.
+// The user should implement the health check
+func (dialect *GroupDialect) Healthy() bool {
+	return dialect.dialect.Healthy()
+}
+
+// Close unsubscribes all subscriptions made through the consumer group view.
+// The dialect the view is constructed from is not closed.
+func (dialect *GroupDialect) Close() error {
+	return dialect.consumer.Close()
+}
+
+// GroupConsumer a message consumer whose subscriptions join a consumer group
+type GroupConsumer struct {
+	consumer      *Consumer
+	group         string
+	subscriptions map[<-chan *types.Message]bool
+	mutex         sync.Mutex
+}
+
+// Subscribe creates a new topic subscription that joins the consumer group
+func (consumer *GroupConsumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription, err := consumer.consumer.SubscribeGroup(consumer.group, topics...)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer.mutex.Lock()
+	consumer.subscriptions[subscription] = true
+	consumer.mutex.Unlock()
+
+	return subscription, nil
+}
+
+// Unsubscribe unsubscribes the given channel subscription from the given topic.
+func (consumer *GroupConsumer) Unsubscribe(subscription <-chan *types.Message) error {
+	consumer.mutex.Lock()
+	delete(consumer.subscriptions, subscription)
+	consumer.mutex.Unlock()
+
+	return consumer.consumer.Unsubscribe(subscription)
+}
+
+// Close unsubscribes all subscriptions made through the group consumer
+func (consumer *GroupConsumer) Close() error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for subscription := range consumer.subscriptions {
+		consumer.consumer.Unsubscribe(subscription)
+		delete(consumer.subscriptions, subscription)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package mock
+
+import "sync"
+
+var closed = make(chan struct{})
+
+func init() {
+	close(closed)
+}
+
+// InFlight keeps track of the amount of messages that are currently being processed.
+type InFlight struct {
+	count int
+	idle  chan struct{}
+	mutex sync.Mutex
+}
+
+// Add marks a new message as in-flight
+func (inflight *InFlight) Add() {
+	inflight.mutex.Lock()
+	defer inflight.mutex.Unlock()
+
+	if inflight.count == 0 {
+		inflight.idle = make(chan struct{}, 0)
+	}
+
+	inflight.count++
+}
+
+// Done marks a in-flight message as processed
+func (inflight *InFlight) Done() {
+	inflight.mutex.Lock()
+	defer inflight.mutex.Unlock()
+
+	inflight.count--
+	if inflight.count == 0 {
+		close(inflight.idle)
+	}
+}
+
+// Count returns the amount of messages that are currently in-flight
+func (inflight *InFlight) Count() int {
+	inflight.mutex.Lock()
+	defer inflight.mutex.Unlock()
+	return inflight.count
+}
+
+// Idle returns a channel that get's closed once no messages are in-flight
+func (inflight *InFlight) Idle() <-chan struct{} {
+	inflight.mutex.Lock()
+	defer inflight.mutex.Unlock()
+
+	if inflight.count == 0 {
+		return closed
+	}
+
+	return inflight.idle
+}
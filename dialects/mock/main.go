@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"os"
 
 	"github.com/jeroenrinzema/commander/internal/types"
@@ -13,7 +14,9 @@ const (
 )
 
 // NewDialect constructs a new in-memory mocking dialect
-func NewDialect() types.Dialect {
+func NewDialect(definitions ...Option) *Dialect {
+	options := NewOptions(definitions)
+
 	logger := log.New()
 	if os.Getenv(DebugEnv) != "" {
 		logger.SetLevel(log.DebugLevel)
@@ -21,17 +24,28 @@ func NewDialect() types.Dialect {
 
 	consumer := &Consumer{
 		subscriptions: make(map[string]*SubscriptionCollection),
+		delivering:    make(map[string]int),
+		retention:     options.Retention,
+		retentionSize: options.RetentionSize,
+		initialOffset: options.InitialOffset,
 		logger:        logger,
 	}
 
 	producer := &Producer{
-		consumer: consumer,
-		logger:   logger,
+		consumer:    consumer,
+		synchronous: options.Synchronous,
+		recorder:    options.Recorder,
+		logger:      logger,
+	}
+
+	if options.Faults != nil {
+		producer.injector = NewInjector(*options.Faults)
 	}
 
 	dialect := &Dialect{
 		consumer: consumer,
 		producer: producer,
+		recorder: options.Recorder,
 		logger:   logger,
 	}
 
@@ -42,6 +56,7 @@ func NewDialect() types.Dialect {
 type Dialect struct {
 	consumer *Consumer
 	producer *Producer
+	recorder *Recorder
 	logger   *log.Logger
 }
 
@@ -68,10 +83,59 @@ func (dialect *Dialect) Healthy() bool {
 	return true
 }
 
+// ConsumerGroup returns a view of the dialect whose subscriptions join the given consumer group.
+// Each message is delivered to a single member of a consumer group, similar to a Kafka consumer group.
+// Multiple views could be constructed to simulate multiple instances of a service.
+func (dialect *Dialect) ConsumerGroup(name string) *GroupDialect {
+	return &GroupDialect{
+		dialect: dialect,
+		consumer: &GroupConsumer{
+			consumer:      dialect.consumer,
+			group:         name,
+			subscriptions: make(map[<-chan *types.Message]bool),
+		},
+	}
+}
+
+// Recorder returns the configured message recorder.
+// Nil is returned if no recorder has been configured.
+func (dialect *Dialect) Recorder() *Recorder {
+	return dialect.recorder
+}
+
+// InjectFaults replaces the faults injected into messages published to the dialect
+func (dialect *Dialect) InjectFaults(faults Faults) {
+	dialect.producer.InjectFaults(faults)
+}
+
+// Flush blocks till all in-flight messages have been processed by the subscribed consumers.
+// Messages produced while processing a in-flight message are awaited as well.
+// Messages held back by injected faults are released and messages negative acknowledged by all members
+// of a consumer group are redelivered before awaiting.
+func (dialect *Dialect) Flush() {
+	dialect.producer.Release()
+	dialect.consumer.Redeliver()
+	<-dialect.consumer.inflight.Idle()
+}
+
+// WaitIdle blocks till all in-flight messages have been processed or till the given context is done.
+// Messages are released and redelivered as done by Flush. The context error is returned if the context got done before the dialect became idle.
+func (dialect *Dialect) WaitIdle(ctx context.Context) error {
+	dialect.producer.Release()
+	dialect.consumer.Redeliver()
+
+	select {
+	case <-dialect.consumer.inflight.Idle():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Close awaits till the consumer(s) and producer(s) of the given dialect are closed.
 // If an error is returned is the closing aborted and the error returned to the user.
 func (dialect *Dialect) Close() error {
-	dialect.consumer.Close()
 	dialect.producer.Close()
+	dialect.consumer.Close()
 	return nil
 }
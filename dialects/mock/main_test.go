@@ -1,6 +1,13 @@
 package mock
 
-import "testing"
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
 
 // TestNewDialectConstruction tests if able to construct a new dialect
 func TestNewDialectConstruction(t *testing.T) {
@@ -25,3 +32,61 @@ func TestNewDialectConstruction(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestDialectFlush tests if flush awaits till all in-flight messages are processed
+func TestDialectFlush(t *testing.T) {
+	dialect := NewDialect()
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var consumed int32
+	go func() {
+		for message := range messages {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&consumed, 1)
+			message.Ack()
+		}
+	}()
+
+	amount := 10
+	for i := 0; i < amount; i++ {
+		message := types.NewMessage("testing", 1, nil, nil)
+		message.Topic = topic
+
+		dialect.Producer().Publish(message)
+	}
+
+	dialect.Flush()
+
+	if atomic.LoadInt32(&consumed) != int32(amount) {
+		t.Fatalf("unexpected amount of consumed messages %d, expected %d", consumed, amount)
+	}
+}
+
+// TestDialectWaitIdleDeadline tests if wait idle returns once the context deadline is reached
+func TestDialectWaitIdleDeadline(t *testing.T) {
+	dialect := NewDialect()
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+
+	_, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("testing", 1, nil, nil)
+	message.Topic = topic
+
+	dialect.Producer().Publish(message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = dialect.WaitIdle(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package mock
+
+import "context"
+
+// Available offsets to start consuming from
+const (
+	OffsetNewest int64 = -1
+	OffsetOldest int64 = -2
+)
+
+// CtxKey typed context key
+type CtxKey string
+
+func (k CtxKey) String() string {
+	return string(k)
+}
+
+const (
+	// CtxOffset represents the mock topic offset context type
+	CtxOffset = CtxKey("mock-offset")
+)
+
+// NewOffsetContext creates a new context with the given topic offset attached.
+// NewOffsetContext will overwrite any previously-appended offset.
+func NewOffsetContext(ctx context.Context, offset int64) context.Context {
+	return context.WithValue(ctx, CtxOffset, offset)
+}
+
+// OffsetFromContext returns the topic offset in ctx if it exists
+func OffsetFromContext(ctx context.Context) (offset int64, ok bool) {
+	offset, ok = ctx.Value(CtxOffset).(int64)
+	return
+}
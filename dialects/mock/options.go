@@ -0,0 +1,78 @@
+package mock
+
+// NewOptions applies the given options to construct a new mock dialect options definition
+func NewOptions(options []Option) (result *Options) {
+	result = &Options{
+		InitialOffset: OffsetNewest,
+	}
+
+	for _, option := range options {
+		option.Apply(result)
+	}
+	return result
+}
+
+// Option sets options such as the delivery mode of the mock dialect
+type Option interface {
+	Apply(*Options)
+}
+
+// Options represent the available set of mock dialect options
+type Options struct {
+	Synchronous   bool
+	Recorder      *Recorder
+	Faults        *Faults
+	Retention     bool
+	RetentionSize int
+	InitialOffset int64
+}
+
+type synchronous struct{}
+
+func (s *synchronous) Apply(options *Options) {
+	options.Synchronous = true
+}
+
+// WithSynchronousDelivery returns a Option that configures the dialect to deliver published messages inline.
+// Publish returns once all subscriptions have acknowledged or negative acknowledged the message.
+// Messages produced by a handler to a topic that is still emitting the consumed (parent) message
+// are delivered in the background instead. Handlers should not await (SyncCommand) messages
+// on the topic they are consuming since the publisher is blocked until the message is resolved.
+func WithSynchronousDelivery() Option {
+	return &synchronous{}
+}
+
+// WithRecorder returns a Option that records all messages published to the dialect with the given recorder
+func WithRecorder(recorder *Recorder) Option {
+	return recorder
+}
+
+type retention struct {
+	size int
+}
+
+func (r *retention) Apply(options *Options) {
+	options.Retention = true
+	options.RetentionSize = r.size
+}
+
+// WithRetention returns a Option that retains the messages published to every topic in memory.
+// The given size limits the amount of retained messages per topic, the oldest messages are
+// removed once the limit is reached. A size of zero or less retains all messages.
+func WithRetention(size int) Option {
+	return &retention{size}
+}
+
+type initialOffset struct {
+	offset int64
+}
+
+func (o *initialOffset) Apply(options *Options) {
+	options.InitialOffset = o.offset
+}
+
+// WithInitialOffset returns a Option that configures the offset new subscriptions start consuming from.
+// The offset could be a absolute offset, OffsetOldest or OffsetNewest (default).
+func WithInitialOffset(offset int64) Option {
+	return &initialOffset{offset}
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"sync"
 	"time"
 
 	"github.com/jeroenrinzema/commander/internal/types"
@@ -9,20 +10,104 @@ import (
 
 // Producer a message producer
 type Producer struct {
-	consumer *Consumer
-	logger   *log.Logger
+	consumer    *Consumer
+	synchronous bool
+	recorder    *Recorder
+	injector    *Injector
+	logger      *log.Logger
+	mutex       sync.RWMutex
 }
 
 // Publish produces a message to the given topic
 func (producer *Producer) Publish(message *types.Message) error {
 	producer.logger.Debug("publishing message")
 
+	producer.mutex.RLock()
+	injector := producer.injector
+	producer.mutex.RUnlock()
+
+	if injector != nil {
+		err := injector.Publish()
+		if err != nil {
+			return err
+		}
+	}
+
 	message.Timestamp = time.Now()
-	go producer.consumer.Emit(message)
+
+	if producer.recorder != nil {
+		producer.recorder.Record(message)
+	}
+
+	deliveries := []*types.Message{message}
+	var latency time.Duration
+
+	if injector != nil {
+		deliveries = injector.Deliveries(message)
+		latency = injector.Latency()
+	}
+
+	producer.deliver(latency, deliveries...)
 	return nil
 }
 
+// InjectFaults replaces the faults injected into published messages.
+// Messages held back by the previous faults are released.
+func (producer *Producer) InjectFaults(faults Faults) {
+	producer.Release()
+
+	producer.mutex.Lock()
+	defer producer.mutex.Unlock()
+
+	producer.injector = NewInjector(faults)
+}
+
+// Release delivers all messages that are held back by the injected faults
+func (producer *Producer) Release() {
+	producer.mutex.RLock()
+	injector := producer.injector
+	producer.mutex.RUnlock()
+
+	if injector == nil {
+		return
+	}
+
+	producer.deliver(0, injector.Release()...)
+}
+
+// deliver emits the given messages in chronological order after the given latency.
+// All messages are marked as in-flight before the method returns.
+func (producer *Producer) deliver(latency time.Duration, messages ...*types.Message) {
+	if len(messages) == 0 {
+		return
+	}
+
+	for range messages {
+		producer.consumer.inflight.Add()
+	}
+
+	emit := func() {
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+
+		for _, message := range messages {
+			producer.consumer.emit(message)
+		}
+	}
+
+	// NOTE: messages published by a handler to a topic that is emitting the message the handler
+	// is consuming are emitted in the background since the topic awaits the handler to return.
+	if producer.synchronous && !producer.consumer.reentrant(messages[0]) {
+		emit()
+		return
+	}
+
+	go emit()
+}
+
 // Close closes the producer
 func (producer *Producer) Close() error {
+	producer.Release()
 	return nil
 }
@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jeroenrinzema/commander/internal/types"
 )
@@ -21,3 +22,84 @@ func TestProducerProduction(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestProducerSynchronousDelivery tests if the producer awaits till the message is resolved
+func TestProducerSynchronousDelivery(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+	message := types.NewMessage("testing", 1, nil, nil)
+	message.Topic = topic
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := false
+	go func() {
+		for message := range messages {
+			consumed = true
+			message.Ack()
+		}
+	}()
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !consumed {
+		t.Fatal("message was not consumed before publish returned")
+	}
+}
+
+// TestProducerSynchronousReentrant tests if a handler is able to produce to the topic it is consuming with synchronous delivery
+func TestProducerSynchronousReentrant(t *testing.T) {
+	dialect := NewDialect(WithSynchronousDelivery())
+	topic := types.NewTopic("mock", dialect, types.EventMessage, types.DefaultMode)
+	message := types.NewMessage("testing", 1, nil, nil)
+	message.Topic = topic
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	children := make(chan *types.Message, 1)
+	go func() {
+		for message := range messages {
+			if message.Action == "testing" {
+				child := message.NewMessage("child", types.NullVersion, nil, nil)
+				child.Topic = topic
+				dialect.Producer().Publish(child)
+			} else {
+				children <- message
+			}
+
+			message.Ack()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- dialect.Producer().Publish(message)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-ctx.Done():
+		t.Fatal("publishing to the consumed topic from a handler deadlocked")
+	}
+
+	select {
+	case <-children:
+	case <-ctx.Done():
+		t.Fatal("produced child message not consumed")
+	}
+}
@@ -0,0 +1,183 @@
+package mock
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+var (
+	// ErrTimeout is returned when a timeout is reached when awaiting a recorded message
+	ErrTimeout = errors.New("timeout reached")
+)
+
+// Matcher reports whether the given message matches a recorder query
+type Matcher func(*types.Message) bool
+
+// WithAction returns a Matcher that matches messages with the given action
+func WithAction(action string) Matcher {
+	return func(message *types.Message) bool {
+		return message.Action == action
+	}
+}
+
+// WithParent returns a Matcher that matches messages that are a child of the given parent id
+func WithParent(parent string) Matcher {
+	return func(message *types.Message) bool {
+		id, has := metadata.ParentIDFromContext(message.Ctx())
+		return has && id == metadata.ParentID(parent)
+	}
+}
+
+// WithStatus returns a Matcher that matches messages with the given status code
+func WithStatus(status types.StatusCode) Matcher {
+	return func(message *types.Message) bool {
+		return message.Status == status
+	}
+}
+
+// WithTopic returns a Matcher that matches messages published to the given topic
+func WithTopic(name string) Matcher {
+	return func(message *types.Message) bool {
+		return message.Topic != nil && message.Topic.Name() == name
+	}
+}
+
+// NewRecorder constructs a new message recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		topics: make(map[string][]*types.Message),
+		notify: make(chan struct{}, 0),
+	}
+}
+
+// Recorder records all messages published to a dialect in chronological order.
+// A recorder could be used to assert which messages got produced during a test.
+type Recorder struct {
+	messages []*types.Message
+	topics   map[string][]*types.Message
+	notify   chan struct{}
+	mutex    sync.RWMutex
+}
+
+// Apply applies the given recorder to the given mock dialect options
+func (recorder *Recorder) Apply(options *Options) {
+	options.Recorder = recorder
+}
+
+// Record records the given message. All awaiting queries are notified of the newly recorded message.
+func (recorder *Recorder) Record(message *types.Message) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.messages = append(recorder.messages, message)
+
+	if message.Topic != nil {
+		name := message.Topic.Name()
+		recorder.topics[name] = append(recorder.topics[name], message)
+	}
+
+	close(recorder.notify)
+	recorder.notify = make(chan struct{}, 0)
+}
+
+// Messages returns all recorded messages in chronological order
+func (recorder *Recorder) Messages() []*types.Message {
+	recorder.mutex.RLock()
+	defer recorder.mutex.RUnlock()
+
+	result := make([]*types.Message, len(recorder.messages))
+	copy(result, recorder.messages)
+
+	return result
+}
+
+// Topic returns all recorded messages of the given topic in chronological order
+func (recorder *Recorder) Topic(name string) []*types.Message {
+	recorder.mutex.RLock()
+	defer recorder.mutex.RUnlock()
+
+	result := make([]*types.Message, len(recorder.topics[name]))
+	copy(result, recorder.topics[name])
+
+	return result
+}
+
+// Find returns all recorded messages matching the given matchers in chronological order
+func (recorder *Recorder) Find(matchers ...Matcher) []*types.Message {
+	recorder.mutex.RLock()
+	defer recorder.mutex.RUnlock()
+
+	return match(recorder.messages, matchers)
+}
+
+// Children returns all recorded child messages of the given parent id in chronological order
+func (recorder *Recorder) Children(parent string) []*types.Message {
+	return recorder.Find(WithParent(parent))
+}
+
+// Await awaits till a message matching the given matchers has been recorded.
+// Messages recorded before Await got called are included. If no message matched
+// within the given timeout period is a ErrTimeout returned.
+func (recorder *Recorder) Await(timeout time.Duration, matchers ...Matcher) (*types.Message, error) {
+	messages, err := recorder.AwaitN(timeout, 1, matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages[0], nil
+}
+
+// AwaitN awaits till n messages matching the given matchers have been recorded.
+// The first n matching messages are returned in chronological order. If less than n
+// messages matched within the given timeout period is a ErrTimeout returned.
+func (recorder *Recorder) AwaitN(timeout time.Duration, n int, matchers ...Matcher) ([]*types.Message, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		recorder.mutex.RLock()
+		messages := match(recorder.messages, matchers)
+		notify := recorder.notify
+		recorder.mutex.RUnlock()
+
+		if len(messages) >= n {
+			return messages[:n], nil
+		}
+
+		select {
+		case <-notify:
+		case <-deadline.C:
+			return nil, ErrTimeout
+		}
+	}
+}
+
+// Reset removes all recorded messages
+func (recorder *Recorder) Reset() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.messages = nil
+	recorder.topics = make(map[string][]*types.Message)
+}
+
+func match(messages []*types.Message, matchers []Matcher) []*types.Message {
+	result := []*types.Message{}
+
+messages:
+	for _, message := range messages {
+		for _, matcher := range matchers {
+			if !matcher(message) {
+				continue messages
+			}
+		}
+
+		result = append(result, message)
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestRecorderFind tests if recorded messages could be queried
+func TestRecorderFind(t *testing.T) {
+	recorder := NewRecorder()
+	dialect := NewDialect(WithRecorder(recorder))
+
+	commands := types.NewTopic("commands", dialect, types.CommandMessage, types.DefaultMode)
+	events := types.NewTopic("events", dialect, types.EventMessage, types.DefaultMode)
+
+	command := types.NewMessage("create", 1, nil, nil)
+	command.Topic = commands
+	dialect.Producer().Publish(command)
+
+	created := command.NewMessage("created", 1, nil, nil)
+	created.Topic = events
+	dialect.Producer().Publish(created)
+
+	failed := command.NewMessage("notified", 1, nil, nil)
+	failed.Topic = events
+	failed.Status = types.StatusInternalServerError
+	dialect.Producer().Publish(failed)
+
+	if len(recorder.Messages()) != 3 {
+		t.Fatalf("unexpected amount of recorded messages %d", len(recorder.Messages()))
+	}
+
+	if len(recorder.Topic(events.Name())) != 2 {
+		t.Fatal("unexpected amount of recorded event messages")
+	}
+
+	children := recorder.Children(command.ID)
+	if len(children) != 2 {
+		t.Fatalf("unexpected amount of child messages %d", len(children))
+	}
+
+	if children[0] != created || children[1] != failed {
+		t.Fatal("child messages are not returned in chronological order")
+	}
+
+	result := recorder.Find(WithParent(command.ID), WithStatus(types.StatusOK), WithTopic(events.Name()))
+	if len(result) != 1 || result[0] != created {
+		t.Fatal("unexpected query result")
+	}
+
+	recorder.Reset()
+
+	if len(recorder.Find()) != 0 {
+		t.Fatal("recorder was not reset")
+	}
+}
+
+// TestRecorderAwait tests if able to await a recorded message
+func TestRecorderAwait(t *testing.T) {
+	recorder := NewRecorder()
+	dialect := NewDialect(WithRecorder(recorder))
+	topic := types.NewTopic("events", dialect, types.EventMessage, types.DefaultMode)
+
+	go func() {
+		for _, action := range []string{"first", "second"} {
+			message := types.NewMessage(action, 1, nil, nil)
+			message.Topic = topic
+			dialect.Producer().Publish(message)
+		}
+	}()
+
+	message, err := recorder.Await(100*time.Millisecond, WithAction("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Action != "second" {
+		t.Fatal("unexpected message returned")
+	}
+
+	messages, err := recorder.AwaitN(100*time.Millisecond, 2, WithTopic(topic.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if messages[0].Action != "first" || messages[1].Action != "second" {
+		t.Fatal("unexpected message order")
+	}
+}
+
+// TestRecorderAwaitTimeout tests if a timeout error is returned when no message is recorded
+func TestRecorderAwaitTimeout(t *testing.T) {
+	recorder := NewRecorder()
+
+	_, err := recorder.Await(10*time.Millisecond, WithAction("unknown"))
+	if err != ErrTimeout {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -1,27 +1,350 @@
 package mock
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/jeroenrinzema/commander/internal/circuit"
 	"github.com/jeroenrinzema/commander/internal/types"
 )
 
+// ErrSubscriptionClosed is returned when delivering a message to a closed subscription
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
 // Subscription mock message subscription
 type Subscription struct {
 	messages chan *types.Message
+	closing  chan struct{}
+	group    string
 	breaker  circuit.Breaker
+	once     sync.Once
+	mutex    sync.Mutex
+}
+
+// NewSubscription constructs a new subscription for the given consumer group
+func NewSubscription(group string) *Subscription {
+	return &Subscription{
+		messages: make(chan *types.Message, 0),
+		closing:  make(chan struct{}),
+		group:    group,
+	}
+}
+
+// Deliver delivers the given message to the subscription and awaits till the message is resolved.
+// A ErrNegativeAcknowledgement error is returned if the message got negative acknowledged.
+// A ErrSubscriptionClosed error is returned if the subscription is closed before the message is received.
+func (subscription *Subscription) Deliver(message *types.Message) error {
+	subscription.mutex.Lock()
+	defer subscription.mutex.Unlock()
+
+	if !subscription.breaker.Safe() {
+		return ErrSubscriptionClosed
+	}
+
+	message.Reset()
+
+	select {
+	case subscription.messages <- message:
+	case <-subscription.closing:
+		return ErrSubscriptionClosed
+	}
+
+	return message.Finally()
+}
+
+// Close closes the subscription messages channel once the received message being delivered is resolved
+func (subscription *Subscription) Close() {
+	subscription.once.Do(func() {
+		subscription.breaker.Open()
+		close(subscription.closing)
+
+		subscription.mutex.Lock()
+		defer subscription.mutex.Unlock()
+
+		close(subscription.messages)
+	})
+}
+
+// ConsumerGroup represents a collection of subscriptions competing for messages
+type ConsumerGroup struct {
+	members   []*Subscription
+	next      int
+	pending   []*types.Message
+	replaying bool
+}
+
+// Emit delivers the given message to a single member of the consumer group.
+// Members are selected in a round robin fashion. The message is redelivered
+// to the next member if it got negative acknowledged, till all members have been attempted.
+// Messages negative acknowledged by all members are kept and redelivered before the next emitted message,
+// once a member joins the group or once the dialect is flushed.
+func (group *ConsumerGroup) Emit(message *types.Message) {
+	group.pending = append(group.pending, message)
+	group.Redeliver()
 }
 
-// SubscriptionCollection represents a collection of subscriptions
+// Redeliver delivers the messages negative acknowledged by all members to the group members.
+// Messages negative acknowledged by all members again are kept for the next redelivery.
+func (group *ConsumerGroup) Redeliver() {
+	messages := group.pending
+	group.pending = nil
+
+	for _, message := range messages {
+		if !group.deliver(message) {
+			group.pending = append(group.pending, message)
+		}
+	}
+}
+
+// deliver delivers the given message to the group members till a member acknowledged the message.
+// False is returned if all members negative acknowledged the message.
+func (group *ConsumerGroup) deliver(message *types.Message) bool {
+	for attempt := 0; attempt < len(group.members); attempt++ {
+		member := group.members[group.next%len(group.members)]
+		group.next++
+
+		err := member.Deliver(message)
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SubscriptionCollection represents a collection of subscriptions of a single topic.
+// Messages emitted to the collection are assigned a offset and retained if retention is enabled.
 type SubscriptionCollection struct {
-	list  map[<-chan *types.Message]*Subscription
-	mutex sync.Mutex
+	list      map[<-chan *types.Message]*Subscription
+	groups    map[string]*ConsumerGroup
+	replaying map[*Subscription]bool
+	offset    int64
+	retain    bool
+	size      int
+	log       []*types.Message
+	emitting  int32
+	inflight  *InFlight
+	mutex     sync.Mutex
 }
 
 // NewTopic constructs a new subscription collection for a topic
 func NewTopic() *SubscriptionCollection {
 	return &SubscriptionCollection{
-		list: map[<-chan *types.Message]*Subscription{},
+		list:      map[<-chan *types.Message]*Subscription{},
+		groups:    map[string]*ConsumerGroup{},
+		replaying: map[*Subscription]bool{},
+	}
+}
+
+// Add adds the given subscription to the collection.
+// If the subscription is part of a consumer group is it added as a member to the group.
+func (collection *SubscriptionCollection) Add(subscription *Subscription) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	collection.add(subscription)
+}
+
+// Subscribe adds the given subscription to the collection and replays the retained messages starting
+// from the given offset. Replaying happens in the background, new messages are emitted once all retained
+// messages have been replayed. Retained messages are not replayed to members joining a existing consumer group.
+func (collection *SubscriptionCollection) Subscribe(subscription *Subscription, offset int64) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	_, existing := collection.groups[subscription.group]
+	collection.add(subscription)
+
+	cursor, has := collection.cursor(offset)
+	if existing || !has {
+		return
+	}
+
+	group := collection.groups[subscription.group]
+	if group != nil {
+		group.replaying = true
+	} else {
+		collection.replaying[subscription] = true
+	}
+
+	if collection.inflight != nil {
+		collection.inflight.Add()
+	}
+
+	go collection.replay(subscription, group, cursor)
+}
+
+// replay delivers the retained messages starting from the given cursor to the given subscription.
+// The collection is unlocked while a message is delivered, messages emitted while replaying are retained
+// and replayed to the subscription before the subscription receives emitted messages again.
+func (collection *SubscriptionCollection) replay(subscription *Subscription, group *ConsumerGroup, cursor int64) {
+	if collection.inflight != nil {
+		defer collection.inflight.Done()
+	}
+
+	for {
+		collection.mutex.Lock()
+
+		base := collection.offset - int64(len(collection.log))
+		if cursor < base {
+			cursor = base
+		}
+
+		if cursor >= collection.offset || collection.list[subscription.messages] != subscription {
+			if group != nil {
+				group.replaying = false
+			}
+
+			delete(collection.replaying, subscription)
+			collection.mutex.Unlock()
+			return
+		}
+
+		// NOTE: a copy is delivered since retained messages could be replayed to multiple subscriptions at once
+		message := collection.log[cursor-base].Copy()
+		cursor++
+		collection.mutex.Unlock()
+
+		subscription.Deliver(message)
+	}
+}
+
+func (collection *SubscriptionCollection) add(subscription *Subscription) {
+	collection.list[subscription.messages] = subscription
+
+	if subscription.group == "" {
+		return
+	}
+
+	group, has := collection.groups[subscription.group]
+	if !has {
+		group = &ConsumerGroup{}
+		collection.groups[subscription.group] = group
+	}
+
+	group.members = append(group.members, subscription)
+}
+
+// cursor returns the absolute offset of the first retained message to be replayed from the given offset.
+// False is returned if no retained messages are available from the given offset.
+func (collection *SubscriptionCollection) cursor(offset int64) (int64, bool) {
+	base := collection.offset - int64(len(collection.log))
+
+	switch {
+	case offset == OffsetNewest || offset >= collection.offset:
+		return 0, false
+	case offset == OffsetOldest || offset < base:
+		offset = base
+	}
+
+	return offset, offset < collection.offset
+}
+
+// Emitting returns true if the collection is emitting a message
+func (collection *SubscriptionCollection) Emitting() bool {
+	return atomic.LoadInt32(&collection.emitting) == 1
+}
+
+// Pending returns the messages kept by the consumer groups of the collection for redelivery
+func (collection *SubscriptionCollection) Pending() []*types.Message {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	pending := []*types.Message{}
+	for _, group := range collection.groups {
+		pending = append(pending, group.pending...)
+	}
+
+	return pending
+}
+
+// Redeliver redelivers the messages kept by the consumer groups of the collection.
+// Groups replaying retained messages redeliver their kept messages once the next message is emitted.
+// The method blocks till the messages are resolved.
+func (collection *SubscriptionCollection) Redeliver() {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	atomic.StoreInt32(&collection.emitting, 1)
+	defer atomic.StoreInt32(&collection.emitting, 0)
+
+	for _, group := range collection.groups {
+		if group.replaying {
+			continue
+		}
+
+		group.Redeliver()
+	}
+}
+
+// Remove removes the given subscription channel from the collection.
+// The removed subscription is returned, nil is returned if the channel was not part of the collection.
+func (collection *SubscriptionCollection) Remove(sub <-chan *types.Message) *Subscription {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	subscription, has := collection.list[sub]
+	if !has {
+		return nil
+	}
+
+	delete(collection.list, sub)
+
+	group, has := collection.groups[subscription.group]
+	if !has {
+		return subscription
+	}
+
+	for index, member := range group.members {
+		if member == subscription {
+			group.members = append(group.members[:index], group.members[index+1:]...)
+			break
+		}
+	}
+
+	if len(group.members) == 0 {
+		delete(collection.groups, subscription.group)
+	}
+
+	return subscription
+}
+
+// Emit assigns the next offset to the given message and delivers the message to all subscriptions
+// that are not part of a consumer group and to a single member of every consumer group.
+// The method blocks till the message is resolved.
+func (collection *SubscriptionCollection) Emit(message *types.Message) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	atomic.StoreInt32(&collection.emitting, 1)
+	defer atomic.StoreInt32(&collection.emitting, 0)
+
+	message.NewCtx(NewOffsetContext(message.Ctx(), collection.offset))
+	collection.offset++
+
+	if collection.retain {
+		collection.log = append(collection.log, message)
+
+		if collection.size > 0 && len(collection.log) > collection.size {
+			collection.log = collection.log[len(collection.log)-collection.size:]
+		}
+	}
+
+	// NOTE: replaying subscriptions receive the emitted message once they have replayed the retained messages
+	for _, subscription := range collection.list {
+		if subscription.group != "" || collection.replaying[subscription] {
+			continue
+		}
+
+		subscription.Deliver(message)
+	}
+
+	for _, group := range collection.groups {
+		if group.replaying {
+			continue
+		}
+
+		group.Emit(message)
 	}
 }
@@ -0,0 +1,92 @@
+package mqtt
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Default config value's
+var (
+	DefaultQoS               byte = 1
+	DefaultKeepAlive              = 30 * time.Second
+	DefaultRetryInterval          = time.Second
+	DefaultConnectionTimeout      = 5 * time.Second
+)
+
+// Custom error types
+var (
+	ErrInvalidQoS = errors.New("invalid qos, expected 0 or 1")
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Broker            string
+	ClientID          string
+	Group             string
+	QoS               byte
+	KeepAlive         time.Duration
+	SessionExpiry     time.Duration
+	RetryInterval     time.Duration
+	ConnectionTimeout time.Duration
+	Username          string
+	Password          string
+}
+
+// NewConfig constructs a Config from the given connection map.
+// If no client id is given is a unique client id generated.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Broker:            strings.TrimPrefix(values[BrokerKey], "tcp://"),
+		ClientID:          values[ClientIDKey],
+		Group:             values[GroupKey],
+		QoS:               DefaultQoS,
+		KeepAlive:         DefaultKeepAlive,
+		RetryInterval:     DefaultRetryInterval,
+		ConnectionTimeout: DefaultConnectionTimeout,
+		Username:          values[UsernameKey],
+		Password:          values[PasswordKey],
+	}
+
+	if config.ClientID == "" {
+		config.ClientID = uuid.Must(uuid.NewV4()).String()
+	}
+
+	if values[QoSKey] != "" {
+		qos, err := strconv.ParseUint(values[QoSKey], 10, 8)
+		if err != nil {
+			return config, err
+		}
+
+		if qos > 1 {
+			return config, ErrInvalidQoS
+		}
+
+		config.QoS = byte(qos)
+	}
+
+	durations := map[string]*time.Duration{
+		KeepAliveKey:         &config.KeepAlive,
+		SessionExpiryKey:     &config.SessionExpiry,
+		RetryIntervalKey:     &config.RetryInterval,
+		ConnectionTimeoutKey: &config.ConnectionTimeout,
+	}
+
+	for key, duration := range durations {
+		if values[key] == "" {
+			continue
+		}
+
+		value, err := time.ParseDuration(values[key])
+		if err != nil {
+			return config, err
+		}
+
+		*duration = value
+	}
+
+	return config, nil
+}
@@ -0,0 +1,71 @@
+package mqtt
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("broker=%s client-id=%s group=%s qos=%s keep-alive=%s session-expiry=%s retry-interval=%s connection-timeout=%s username=%s password=%s", val, val, val, val, val, val, val, val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		BrokerKey,
+		ClientIDKey,
+		GroupKey,
+		QoSKey,
+		KeepAliveKey,
+		SessionExpiryKey,
+		RetryIntervalKey,
+		ConnectionTimeoutKey,
+		UsernameKey,
+		PasswordKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		BrokerKey:        "tcp://localhost:1883",
+		QoSKey:           "0",
+		SessionExpiryKey: "1h",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Broker != "localhost:1883" {
+		t.Fatal("Broker not set")
+	}
+
+	if conf.ClientID == "" {
+		t.Fatal("No client id generated")
+	}
+
+	if conf.QoS != 0 {
+		t.Fatal("QoS not set")
+	}
+
+	if conf.SessionExpiry != time.Hour || conf.KeepAlive != DefaultKeepAlive {
+		t.Fatal("Unexpected durations")
+	}
+}
+
+// TestNewConfigInvalidQoS tests if a error is returned for a unsupported QoS
+func TestNewConfigInvalidQoS(t *testing.T) {
+	_, err := NewConfig(ConnectionMap{QoSKey: "2"})
+	if err != ErrInvalidQoS {
+		t.Fatal("unexpected error")
+	}
+}
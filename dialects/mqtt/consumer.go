@@ -0,0 +1,350 @@
+package mqtt
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Delivery represents a received MQTT publish packet queued for one or multiple topics.
+// The packet is acknowledged once it has been claimed by all topics.
+type Delivery struct {
+	received paho.PublishReceived
+	pending  int32
+}
+
+// Done marks the delivery as claimed by a single topic and acknowledges the packet once claimed by all topics
+func (delivery *Delivery) Done() {
+	if atomic.AddInt32(&delivery.pending, -1) > 0 {
+		return
+	}
+
+	err := delivery.received.Client.Ack(delivery.received.Packet)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// Topic represents a thread safe list of subscriptions and the queue of packets received for the topic
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	queue         []*Delivery
+	notify        chan struct{}
+	mutex         sync.RWMutex
+	queueMutex    sync.Mutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+		notify:        make(chan struct{}, 1),
+	}
+}
+
+// Push queues the given delivery to be claimed by the topic
+func (topic *Topic) Push(delivery *Delivery) {
+	topic.queueMutex.Lock()
+	topic.queue = append(topic.queue, delivery)
+	topic.queueMutex.Unlock()
+
+	topic.Notify()
+}
+
+// Notify notifies the topic processor about a queued packet or a new subscription
+func (topic *Topic) Notify() {
+	select {
+	case topic.notify <- struct{}{}:
+	default:
+	}
+}
+
+// NewConsumer constructs a new MQTT consumer
+func NewConsumer(config Config) *Consumer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	consumer := &Consumer{
+		config: config,
+		topics: make(map[string]*Topic),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	return consumer
+}
+
+// Consumer consumes MQTT messages of the subscribed topic filters.
+// Topic names are used as MQTT topic filters and could contain wildcards.
+// Received messages are queued per topic and delivered in order of arrival outside of the MQTT client router.
+// Messages are acknowledged (PUBACK) once all subscriptions of all matching topics acknowledged the message.
+// Messages are held back while no subscriptions are made for a matching topic without holding back other topics.
+// Negative acknowledged messages are redelivered to the subscriptions that negative acknowledged the message
+// after the configured retry interval.
+type Consumer struct {
+	client *paho.Client
+	config Config
+	topics map[string]*Topic
+	ctx    context.Context
+	cancel context.CancelFunc
+	loops  sync.WaitGroup
+	mutex  sync.RWMutex
+}
+
+// topic returns the topic collection of the given topic, a new collection is constructed
+// and processed if the topic has not been registered before. The consumer mutex should be locked.
+func (consumer *Consumer) topic(topic types.Topic) *Topic {
+	collection, has := consumer.topics[topic.Name()]
+	if has {
+		return collection
+	}
+
+	collection = NewTopic(topic)
+	consumer.topics[topic.Name()] = collection
+
+	if consumer.ctx.Err() == nil {
+		consumer.loops.Add(1)
+		go consumer.Process(collection)
+	}
+
+	return collection
+}
+
+// Connect subscribes to the topic filters of the given topics.
+// If a group is configured are the topics subscribed to as shared subscriptions.
+func (consumer *Consumer) Connect(ctx context.Context, client *paho.Client, topics ...types.Topic) error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	consumer.client = client
+	subscriptions := []paho.SubscribeOptions{}
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		consumer.topic(topic)
+
+		subscriptions = append(subscriptions, paho.SubscribeOptions{
+			Topic: SharedFilter(consumer.config.Group, topic.Name()),
+			QoS:   consumer.config.QoS,
+		})
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	_, err := client.Subscribe(ctx, &paho.Subscribe{
+		Subscriptions: subscriptions,
+	})
+
+	return err
+}
+
+// OnPublishReceived queues the received MQTT publish packets to be processed by the matching topics.
+// Packets not matching any consumed topic are acknowledged directly.
+// The MQTT client sends the acknowledgements in order of arrival.
+func (consumer *Consumer) OnPublishReceived(received paho.PublishReceived) (bool, error) {
+	pb := received.Packet
+
+	consumer.mutex.RLock()
+	matches := []*Topic{}
+	for filter, topic := range consumer.topics {
+		if topic.topic.HasMode(types.ConsumeMode) && Match(filter, pb.Topic) {
+			matches = append(matches, topic)
+		}
+	}
+	consumer.mutex.RUnlock()
+
+	if len(matches) == 0 {
+		err := received.Client.Ack(pb)
+		if err != nil {
+			log.Error(err)
+		}
+
+		return true, nil
+	}
+
+	delivery := &Delivery{
+		received: received,
+		pending:  int32(len(matches)),
+	}
+
+	for _, topic := range matches {
+		topic.Push(delivery)
+	}
+
+	return true, nil
+}
+
+// Process claims the queued publish packets of the given topic in order of arrival till the consumer is closed
+func (consumer *Consumer) Process(topic *Topic) {
+	defer consumer.loops.Done()
+
+	for {
+		delivery, ok := consumer.next(topic)
+		if !ok {
+			return
+		}
+
+		if !consumer.Claim(topic, delivery.received.Packet) {
+			return
+		}
+
+		delivery.Done()
+	}
+}
+
+// next awaits and returns the next queued delivery of the given topic.
+// False is returned once the consumer is closed.
+func (consumer *Consumer) next(topic *Topic) (*Delivery, bool) {
+	for {
+		topic.queueMutex.Lock()
+		if len(topic.queue) > 0 {
+			delivery := topic.queue[0]
+			topic.queue = topic.queue[1:]
+			topic.queueMutex.Unlock()
+			return delivery, true
+		}
+		topic.queueMutex.Unlock()
+
+		select {
+		case <-consumer.ctx.Done():
+			return nil, false
+		case <-topic.notify:
+		}
+	}
+}
+
+// Claim emit's the given publish packet as message to the subscriptions of the given topic.
+// All subscriptions are awaited untill done. The message is redelivered once the retry interval is reached
+// to the subscriptions that negative acknowledged the message, or once a subscription is made if the topic
+// has no subscriptions. A boolean is returned representing if the message has been acknowledged,
+// false is returned once the consumer is closed.
+func (consumer *Consumer) Claim(topic *Topic, pb *paho.Publish) bool {
+	acknowledged := map[*Subscription]bool{}
+
+	for {
+		if consumer.deliver(topic, pb, acknowledged) {
+			return true
+		}
+
+		select {
+		case <-consumer.ctx.Done():
+			return false
+		case <-topic.notify:
+			// NOTE: notifications about queued packets are not lost, the queue is checked before the processor awaits a notification
+		case <-time.After(consumer.config.RetryInterval):
+		}
+	}
+}
+
+// deliver delivers the given publish packet as message to all subscriptions of the given topic
+// that have not yet acknowledged the message. Subscriptions acknowledging the message are added to the given set.
+// A boolean is returned representing if the message has been acknowledged by all subscriptions.
+func (consumer *Consumer) deliver(topic *Topic, pb *paho.Publish, acknowledged map[*Subscription]bool) bool {
+	topic.mutex.RLock()
+	defer topic.mutex.RUnlock()
+
+	if len(topic.subscriptions) == 0 {
+		return false
+	}
+
+	result := true
+
+	for _, subscription := range topic.subscriptions {
+		if acknowledged[subscription] {
+			continue
+		}
+
+		message := MessageFromPublish(topic.topic, pb)
+		message.Reset()
+
+		select {
+		case subscription.messages <- message:
+		case <-consumer.ctx.Done():
+			return false
+		}
+
+		err := message.Finally()
+		if err != nil {
+			log.Debug("message negative acknowledged, awaiting redelivery")
+			result = false
+			continue
+		}
+
+		acknowledged[subscription] = true
+	}
+
+	return result
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		collection := consumer.topic(topic)
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+		collection.Notify()
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Close stops delivering and redelivering messages. Messages that have not been
+// acknowledged are redelivered by the broker once a persistent session is resumed.
+func (consumer *Consumer) Close() error {
+	// NOTE: the consumer is locked to avoid new topic processors being started while awaiting the running processors
+	consumer.mutex.Lock()
+	consumer.cancel()
+	consumer.mutex.Unlock()
+
+	consumer.loops.Wait()
+	return nil
+}
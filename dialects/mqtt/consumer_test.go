@@ -0,0 +1,317 @@
+package mqtt
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestConsumerConsumption tests if produced messages are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("devices/telemetry", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+		t.Fatal("message key or data not preserved")
+	}
+
+	if !consumed.EOS || consumed.Status != types.StatusNotFound {
+		t.Fatal("message eos or status not preserved")
+	}
+
+	id, has := metadata.ParentIDFromContext(consumed.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+}
+
+// TestConsumerWildcard tests if messages published to topics matching a wildcard topic filter are consumed
+func TestConsumerWildcard(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	telemetry := types.NewTopic("devices/+/telemetry", nil, types.EventMessage, types.ConsumeMode)
+	dialect := NewTestDialect(t, broker, "", telemetry)
+
+	messages, err := dialect.Consumer().Subscribe(telemetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer := NewTestDialect(t, broker, "")
+
+	message := types.NewMessage("temperature", 1, nil, []byte("21.5"))
+	message.Topic = types.NewTopic("devices/42/telemetry", nil, types.EventMessage, types.ProduceMode)
+
+	err = producer.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.Topic != telemetry {
+		t.Fatal("unexpected message topic")
+	}
+
+	topic, has := TopicFromContext(consumed.Ctx())
+	if !has || topic != "devices/42/telemetry" {
+		t.Fatal("MQTT topic not set")
+	}
+}
+
+// TestProducerWildcard tests if publishing to a wildcard topic returns a error unless a MQTT topic is defined
+func TestProducerWildcard(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("devices/+/commands", nil, types.CommandMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("reboot", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != ErrWildcardTopic {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message.NewCtx(NewTopicContext(message.Ctx(), "devices/42/commands"))
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Await(t, messages).Ack()
+}
+
+// TestConsumerNackRedelivery tests if negative acknowledged messages are redelivered
+func TestConsumerNackRedelivery(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "retry-interval=10ms", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Await(t, messages).Nack()
+
+	redelivered := testutil.Await(t, messages)
+	defer redelivered.Ack()
+
+	if redelivered.ID != message.ID {
+		t.Fatal("unexpected message redelivered")
+	}
+}
+
+// TestConsumerNackRedeliverySubscription tests if negative acknowledged messages are only redelivered to the subscription that negative acknowledged the message
+func TestConsumerNackRedeliverySubscription(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "retry-interval=10ms", topic)
+
+	failing, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	succeeding, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := 0
+	nacked := false
+
+	for consumed < 2 {
+		select {
+		case message := <-failing:
+			if !nacked {
+				nacked = true
+				message.Nack()
+				continue
+			}
+
+			consumed++
+			message.Ack()
+		case message := <-succeeding:
+			consumed++
+			message.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("timeout reached")
+		}
+	}
+
+	select {
+	case <-succeeding:
+		t.Fatal("message redelivered to a subscription which acknowledged the message")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestConsumerLateSubscription tests if messages received before a subscription is made are delivered once subscribed
+func TestConsumerLateSubscription(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "retry-interval=1h", topic)
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err := dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != message.ID {
+		t.Fatal("unexpected message consumed")
+	}
+}
+
+// TestConsumerSharedSubscription tests if messages are load balanced between dialects in the same group
+func TestConsumerSharedSubscription(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+	consumed := int32(0)
+
+	for i := 0; i < 2; i++ {
+		dialect := NewTestDialect(t, broker, "group=mock", topic)
+		messages, err := dialect.Consumer().Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			for message := range messages {
+				atomic.AddInt32(&consumed, 1)
+				message.Ack()
+			}
+		}()
+	}
+
+	producer := NewTestDialect(t, broker, "")
+
+	for i := 0; i < 10; i++ {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err := producer.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&consumed) < 10 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if atomic.LoadInt32(&consumed) != 10 {
+		t.Fatalf("unexpected amount of consumed messages: %d", consumed)
+	}
+}
+
+// TestConsumerIdleTopic tests if messages of a topic without subscriptions do not hold back the messages of other topics
+func TestConsumerIdleTopic(t *testing.T) {
+	broker := NewTestBroker(t)
+
+	events := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	commands := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, broker, "retry-interval=1h", events, commands)
+
+	messages, err := dialect.Consumer().Subscribe(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := types.NewMessage("event", 1, nil, nil)
+	event.Topic = events
+
+	err = dialect.Producer().Publish(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command := types.NewMessage("command", 1, nil, nil)
+	command.Topic = commands
+
+	err = dialect.Producer().Publish(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != command.ID {
+		t.Fatal("unexpected message consumed")
+	}
+}
@@ -0,0 +1,150 @@
+package mqtt
+
+import (
+	"context"
+	"net"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Dialect represents the MQTT dialect
+type Dialect struct {
+	Connection Config
+
+	client   *paho.Client
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new MQTT v5 dialect.
+// Topic names are used as MQTT topics and topic filters, consumed topics could contain
+// single (+) and multi (#) level wildcards. Commander metadata is carried as MQTT v5 user properties.
+// If a group is defined in the connectionstring are the topics subscribed to as shared
+// subscriptions, messages are then load balanced between all dialects within the same group.
+func NewDialect(connectionstring string) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := &Dialect{
+		Connection: connection,
+		consumer:   NewConsumer(connection),
+		producer:   NewProducer(connection),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open opens a MQTT connection and subscribes to the given topics.
+// A persistent session is requested if a session expiry is configured, messages that have not
+// been acknowledged are then redelivered by the broker once the session is resumed.
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialect.Connection.ConnectionTimeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", dialect.Connection.Broker)
+	if err != nil {
+		return err
+	}
+
+	client := paho.NewClient(paho.ClientConfig{
+		ClientID:                   dialect.Connection.ClientID,
+		Conn:                       conn,
+		EnableManualAcknowledgment: true,
+		OnPublishReceived: []func(paho.PublishReceived) (bool, error){
+			dialect.consumer.OnPublishReceived,
+		},
+	})
+
+	expiry := uint32(dialect.Connection.SessionExpiry.Seconds())
+	connect := &paho.Connect{
+		ClientID:   dialect.Connection.ClientID,
+		KeepAlive:  uint16(dialect.Connection.KeepAlive.Seconds()),
+		CleanStart: expiry == 0,
+		Properties: &paho.ConnectProperties{
+			SessionExpiryInterval: &expiry,
+			// Some brokers omit user properties if no problem information is requested
+			RequestProblemInfo: true,
+		},
+	}
+
+	if dialect.Connection.Username != "" {
+		connect.Username = dialect.Connection.Username
+		connect.UsernameFlag = true
+	}
+
+	if dialect.Connection.Password != "" {
+		connect.Password = []byte(dialect.Connection.Password)
+		connect.PasswordFlag = true
+	}
+
+	_, err = client.Connect(ctx, connect)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	dialect.client = client
+	dialect.producer.Connect(client)
+
+	return dialect.consumer.Connect(ctx, client, topics...)
+}
+
+// Close closes the MQTT consumers, producers and connection
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	if dialect.client == nil {
+		return nil
+	}
+
+	err = dialect.client.Disconnect(&paho.Disconnect{ReasonCode: 0})
+	if err != nil {
+		return err
+	}
+
+	<-dialect.client.Done()
+	return nil
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	if dialect.client == nil {
+		return false
+	}
+
+	select {
+	case <-dialect.client.Done():
+		return false
+	default:
+		return true
+	}
+}
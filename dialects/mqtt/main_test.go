@@ -0,0 +1,85 @@
+package mqtt
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+	server "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// NewTestBroker starts a new embedded MQTT broker on a random port.
+// The broker is closed once the test is completed.
+func NewTestBroker(t *testing.T) string {
+	broker := server.New(&server.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	err := broker.AddHook(new(auth.AllowHook), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := listeners.NewTCP(listeners.Config{
+		ID:      "tcp",
+		Address: "127.0.0.1:0",
+	})
+
+	err = broker.AddListener(listener)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = broker.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		broker.Close()
+	})
+
+	return listener.Address()
+}
+
+// NewTestDialect constructs and opens a new MQTT dialect for the given broker, connectionstring options and topics
+func NewTestDialect(t *testing.T, broker string, options string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect("broker=" + broker + " " + options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new MQTT dialect
+func TestNewDialect(t *testing.T) {
+	broker := NewTestBroker(t)
+	dialect := NewTestDialect(t, broker, "")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dialect.Healthy() {
+		t.Fatal("closed dialect reported as healthy")
+	}
+}
+
+// TestNewDialectNoBroker tests if a error is returned when no broker is defined
+func TestNewDialectNoBroker(t *testing.T) {
+	_, err := NewDialect("group=mock")
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
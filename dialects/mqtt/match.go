@@ -0,0 +1,49 @@
+package mqtt
+
+import "strings"
+
+// MQTT topic wildcards
+const (
+	SingleLevelWildcard = "+"
+	MultiLevelWildcard  = "#"
+	TopicSeparator      = "/"
+)
+
+// IsWildcard returns a boolean representing if the given topic filter contains wildcards
+func IsWildcard(filter string) bool {
+	return strings.Contains(filter, SingleLevelWildcard) || strings.Contains(filter, MultiLevelWildcard)
+}
+
+// Match returns a boolean representing if the given topic matches the given topic filter.
+// A single level wildcard (+) matches exactly one topic level, a multi level wildcard (#)
+// matches the parent level and any number of child levels.
+func Match(filter string, topic string) bool {
+	filters := strings.Split(filter, TopicSeparator)
+	levels := strings.Split(topic, TopicSeparator)
+
+	for index, level := range filters {
+		if level == MultiLevelWildcard {
+			return true
+		}
+
+		if index >= len(levels) {
+			return false
+		}
+
+		if level != SingleLevelWildcard && level != levels[index] {
+			return false
+		}
+	}
+
+	return len(filters) == len(levels)
+}
+
+// SharedFilter returns the shared subscription filter of the given topic filter for the given group.
+// Messages of shared subscriptions are load balanced between all subscribers of the group.
+func SharedFilter(group string, filter string) string {
+	if group == "" {
+		return filter
+	}
+
+	return "$share/" + group + "/" + filter
+}
@@ -0,0 +1,39 @@
+package mqtt
+
+import "testing"
+
+// TestMatch tests if topics are matched against topic filters
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter   string
+		topic    string
+		expected bool
+	}{
+		{"devices/telemetry", "devices/telemetry", true},
+		{"devices/telemetry", "devices/status", false},
+		{"devices/+/telemetry", "devices/42/telemetry", true},
+		{"devices/+/telemetry", "devices/42/status", false},
+		{"devices/+/telemetry", "devices/42/telemetry/temperature", false},
+		{"devices/#", "devices", true},
+		{"devices/#", "devices/42/telemetry", true},
+		{"devices/+", "devices", false},
+		{"#", "devices/42", true},
+	}
+
+	for _, test := range tests {
+		if Match(test.filter, test.topic) != test.expected {
+			t.Errorf("unexpected match result for %s, %s", test.filter, test.topic)
+		}
+	}
+}
+
+// TestSharedFilter tests if shared subscription filters are constructed for groups
+func TestSharedFilter(t *testing.T) {
+	if SharedFilter("", "devices/#") != "devices/#" {
+		t.Fatal("unexpected filter without group")
+	}
+
+	if SharedFilter("group", "devices/#") != "$share/group/devices/#" {
+		t.Fatal("unexpected shared filter")
+	}
+}
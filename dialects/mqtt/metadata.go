@@ -0,0 +1,84 @@
+package mqtt
+
+import (
+	"context"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// MessageFromPublish constructs a commander message of the given MQTT publish packet.
+// The commander metadata is extracted from the MQTT v5 user properties.
+func MessageFromPublish(topic types.Topic, pb *paho.Publish) *types.Message {
+	message := &types.Message{
+		Topic:     topic,
+		Data:      pb.Payload,
+		Status:    types.StatusOK,
+		Timestamp: time.Now(),
+	}
+
+	message.NewCtx(NewTopicContext(context.Background(), pb.Topic))
+
+	kv := map[string]string{}
+	if pb.Properties != nil {
+		for _, property := range pb.Properties.User {
+			kv[property.Key] = property.Value
+		}
+	}
+
+	headers.Unmarshal(message, kv)
+
+	if message.Key == nil {
+		message.Key = []byte(message.ID)
+	}
+
+	return message
+}
+
+// MessageToPublish constructs a MQTT publish packet of the given commander message for the given topic.
+// MQTT has no notion of message keys, the message key is therefore included as a user property.
+func MessageToPublish(topic string, qos byte, message *types.Message) *paho.Publish {
+	properties := paho.UserProperties{}
+	for key, value := range headers.Marshal(message) {
+		properties.Add(key, value)
+	}
+
+	properties.Add(headers.HeaderKey, string(message.Key))
+
+	return &paho.Publish{
+		Topic:   topic,
+		QoS:     qos,
+		Payload: message.Data,
+		Properties: &paho.PublishProperties{
+			User: properties,
+		},
+	}
+}
+
+// CtxKey typed context key
+type CtxKey string
+
+func (k CtxKey) String() string {
+	return string(k)
+}
+
+const (
+	// CtxTopic represents the MQTT topic context type
+	CtxTopic = CtxKey("mqtt-topic")
+)
+
+// NewTopicContext creates a new context with the given MQTT topic attached.
+// Consumed messages include the topic the message was published to, which could differ from the
+// (wildcard) topic filter of the commander topic. Produced messages including a MQTT topic are
+// published to the given topic instead of the commander topic name.
+func NewTopicContext(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, CtxTopic, topic)
+}
+
+// TopicFromContext returns the MQTT topic in ctx if it exists
+func TopicFromContext(ctx context.Context) (topic string, ok bool) {
+	topic, ok = ctx.Value(CtxTopic).(string)
+	return
+}
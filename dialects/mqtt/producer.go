@@ -0,0 +1,61 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Custom error types
+var (
+	ErrWildcardTopic = errors.New("unable to publish to a wildcard topic, define a MQTT topic inside the message context")
+)
+
+// NewProducer constructs a new MQTT producer
+func NewProducer(config Config) *Producer {
+	return &Producer{
+		config: config,
+	}
+}
+
+// Producer publishes messages to MQTT topics
+type Producer struct {
+	client     *paho.Client
+	config     Config
+	production sync.WaitGroup
+}
+
+// Connect assigns the given MQTT client to the producer
+func (producer *Producer) Connect(client *paho.Client) {
+	producer.client = client
+}
+
+// Publish publishes the given message to the MQTT topic of the message.
+// The message is published to the MQTT topic inside the message context if defined,
+// otherwise is the message published to the message topic name. QoS 1 messages are
+// awaited till acknowledged by the broker.
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	topic, has := TopicFromContext(message.Ctx())
+	if !has {
+		topic = message.Topic.Name()
+	}
+
+	if IsWildcard(topic) {
+		return ErrWildcardTopic
+	}
+
+	_, err := producer.client.Publish(context.Background(), MessageToPublish(topic, producer.config.QoS, message))
+	return err
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
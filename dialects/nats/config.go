@@ -0,0 +1,36 @@
+package nats
+
+import (
+	"strings"
+	"time"
+)
+
+// Default config value's
+var (
+	DefaultConnectionTimeout = 5 * time.Second
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Servers           []string
+	Group             string
+	Name              string
+	ConnectionTimeout time.Duration
+}
+
+// NewConfig constructs a Config from the given connection map
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{}
+
+	connectionTimeout, err := time.ParseDuration(values[ConnectionTimeoutKey])
+	if err != nil {
+		connectionTimeout = DefaultConnectionTimeout
+	}
+
+	config.Servers = strings.Split(values[ServersKey], ",")
+	config.Group = values[GroupKey]
+	config.Name = values[NameKey]
+	config.ConnectionTimeout = connectionTimeout
+
+	return config, nil
+}
@@ -0,0 +1,53 @@
+package nats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("servers=%s group=%s name=%s connection-timeout=%s", val, val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		ServersKey,
+		GroupKey,
+		NameKey,
+		ConnectionTimeoutKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		ServersKey:           "nats://a:4222,nats://b:4222",
+		GroupKey:             "group",
+		ConnectionTimeoutKey: "1s",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conf.Servers) != 2 {
+		t.Fatal("Servers not set")
+	}
+
+	if conf.Group != values[GroupKey] {
+		t.Fatal("Group not set")
+	}
+
+	if conf.ConnectionTimeout != 1*time.Second {
+		t.Fatal("No connection timeout was set")
+	}
+}
@@ -0,0 +1,173 @@
+package nats
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Topic represents a thread safe list of subscriptions
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	mutex         sync.RWMutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+	}
+}
+
+// NewConsumer constructs a new NATS consumer
+func NewConsumer(group string) *Consumer {
+	return &Consumer{
+		group:  group,
+		topics: make(map[string]*Topic),
+	}
+}
+
+// Consumer consumes NATS messages
+type Consumer struct {
+	conn          *nats.Conn
+	group         string
+	topics        map[string]*Topic
+	subscriptions []*nats.Subscription
+	consumptions  sync.WaitGroup
+	mutex         sync.RWMutex
+}
+
+// Connect subscribes to the given topics on the given NATS connection.
+// If a consumer group is configured are the topics subscribed to as a queue group,
+// messages are then load balanced between all members of the queue group.
+func (consumer *Consumer) Connect(conn *nats.Conn, topics ...types.Topic) error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	consumer.conn = conn
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		subscription, err := conn.QueueSubscribe(topic.Name(), consumer.group, consumer.Claim)
+		if err != nil {
+			return err
+		}
+
+		consumer.subscriptions = append(consumer.subscriptions, subscription)
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		collection := consumer.topics[topic.Name()]
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Claim consumes and emit's the given NATS message to the subscribed
+// subscriptions. All subscriptions are awaited untill done. NATS does not
+// support redelivery, negative acknowledged messages are logged and dropped.
+func (consumer *Consumer) Claim(msg *nats.Msg) {
+	consumer.consumptions.Add(1)
+	defer consumer.consumptions.Done()
+
+	consumer.mutex.RLock()
+	topic := consumer.topics[msg.Subject]
+	consumer.mutex.RUnlock()
+
+	if topic == nil {
+		return
+	}
+
+	message := MessageFromMsg(topic.topic, msg)
+
+	topic.mutex.RLock()
+	defer topic.mutex.RUnlock()
+
+	for _, subscription := range topic.subscriptions {
+		message.Reset()
+		subscription.messages <- message
+
+		err := message.Finally()
+		if err != nil {
+			log.Debugf("message %s negative acknowledged, NATS does not support redelivery", message.ID)
+		}
+	}
+}
+
+// Close drains all NATS subscriptions and awaits till all claimed messages are processed
+func (consumer *Consumer) Close() error {
+	consumer.mutex.Lock()
+	subscriptions := consumer.subscriptions
+	consumer.subscriptions = nil
+	consumer.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		err := subscription.Unsubscribe()
+		if err != nil {
+			return err
+		}
+	}
+
+	consumer.consumptions.Wait()
+	return nil
+}
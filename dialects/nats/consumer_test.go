@@ -0,0 +1,110 @@
+package nats
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestConsumerConsumption tests if produced messages are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	srv := NewTestServer(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, srv, "", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case consumed := <-messages:
+		defer consumed.Ack()
+
+		if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+			t.Fatal("message id, action or version not preserved")
+		}
+
+		if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+			t.Fatal("message key or data not preserved")
+		}
+
+		if !consumed.EOS || consumed.Status != types.StatusNotFound {
+			t.Fatal("message eos or status not preserved")
+		}
+
+		if consumed.Topic != topic {
+			t.Fatal("unexpected message topic")
+		}
+
+		id, has := metadata.ParentIDFromContext(consumed.Ctx())
+		if !has || string(id) != parent.ID {
+			t.Fatal("parent id not preserved")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout reached")
+	}
+}
+
+// TestConsumerQueueGroup tests if messages are load balanced between dialects in the same group
+func TestConsumerQueueGroup(t *testing.T) {
+	srv := NewTestServer(t)
+
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+	consumed := int32(0)
+
+	for i := 0; i < 2; i++ {
+		dialect := NewTestDialect(t, srv, "service", topic)
+		messages, err := dialect.Consumer().Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			for message := range messages {
+				atomic.AddInt32(&consumed, 1)
+				message.Ack()
+			}
+		}()
+	}
+
+	producer := NewTestDialect(t, srv, "")
+
+	amount := 10
+	for i := 0; i < amount; i++ {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err := producer.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&consumed) < int32(amount) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Allow potential duplicate deliveries to arrive
+	time.Sleep(50 * time.Millisecond)
+
+	if atomic.LoadInt32(&consumed) != int32(amount) {
+		t.Fatalf("unexpected amount of consumed messages %d, expected %d", consumed, amount)
+	}
+}
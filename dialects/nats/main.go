@@ -0,0 +1,107 @@
+package nats
+
+import (
+	"strings"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/nats-io/nats.go"
+)
+
+// Dialect represents the NATS dialect
+type Dialect struct {
+	Connection Config
+	Options    []nats.Option
+
+	conn     *nats.Conn
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new NATS dialect.
+// Topic names are used as NATS subjects. If a group is defined in the connectionstring
+// are the subjects subscribed to as a queue group, messages are then load balanced
+// between all dialects within the same group.
+func NewDialect(connectionstring string, options ...nats.Option) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := &Dialect{
+		Connection: connection,
+		Options:    options,
+		consumer:   NewConsumer(connection.Group),
+		producer:   NewProducer(),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open opens a NATS connection and subscribes to the given topics
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	options := append([]nats.Option{
+		nats.Name(dialect.Connection.Name),
+		nats.Timeout(dialect.Connection.ConnectionTimeout),
+	}, dialect.Options...)
+
+	conn, err := nats.Connect(strings.Join(dialect.Connection.Servers, ","), options...)
+	if err != nil {
+		return err
+	}
+
+	dialect.conn = conn
+	dialect.producer.Connect(conn)
+
+	err = dialect.consumer.Connect(conn, topics...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the NATS consumers and producers
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	if dialect.conn != nil {
+		dialect.conn.Close()
+	}
+
+	return nil
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	if dialect.conn == nil {
+		return false
+	}
+
+	return dialect.conn.IsConnected()
+}
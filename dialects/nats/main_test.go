@@ -0,0 +1,73 @@
+package nats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// NewTestServer starts a new in-process NATS server on a random port.
+// The server is shut down once the test is completed.
+func NewTestServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go srv.Start()
+
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready for connections")
+	}
+
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+// NewTestDialect constructs and opens a new NATS dialect for the given server, group and topics
+func NewTestDialect(t *testing.T, srv *server.Server, group string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect(fmt.Sprintf("servers=%s group=%s", srv.ClientURL(), group))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new NATS dialect
+func TestNewDialect(t *testing.T) {
+	srv := NewTestServer(t)
+	dialect := NewTestDialect(t, srv, "")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dialect.Healthy() {
+		t.Fatal("closed dialect reported as healthy")
+	}
+}
+
+// TestNewDialectNoServers tests if a error is returned when no servers are defined
+func TestNewDialectNoServers(t *testing.T) {
+	_, err := NewDialect("group=mock")
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
@@ -0,0 +1,50 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/nats-io/nats.go"
+)
+
+// MessageFromMsg constructs a commander message of the given NATS message.
+// The commander metadata is extracted from the NATS message headers.
+func MessageFromMsg(topic types.Topic, msg *nats.Msg) *types.Message {
+	message := &types.Message{
+		Topic:     topic,
+		Data:      msg.Data,
+		Status:    types.StatusOK,
+		Timestamp: time.Now(),
+	}
+
+	message.NewCtx(context.Background())
+
+	kv := map[string]string{}
+	for key := range msg.Header {
+		kv[key] = msg.Header.Get(key)
+	}
+
+	headers.Unmarshal(message, kv)
+
+	if message.Key == nil {
+		message.Key = []byte(message.ID)
+	}
+
+	return message
+}
+
+// MessageToMsg constructs a NATS message of the given commander message.
+// NATS has no notion of message keys, the message key is therefore included as a header.
+func MessageToMsg(message *types.Message) *nats.Msg {
+	msg := nats.NewMsg(message.Topic.Name())
+	msg.Data = message.Data
+
+	for key, value := range headers.Marshal(message) {
+		msg.Header.Set(key, value)
+	}
+
+	msg.Header.Set(headers.HeaderKey, string(message.Key))
+	return msg
+}
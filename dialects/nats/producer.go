@@ -0,0 +1,43 @@
+package nats
+
+import (
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/nats-io/nats.go"
+)
+
+// NewProducer constructs a new NATS producer
+func NewProducer() *Producer {
+	return &Producer{}
+}
+
+// Producer produces NATS messages
+type Producer struct {
+	conn       *nats.Conn
+	production sync.WaitGroup
+}
+
+// Connect assigns the given NATS connection to the producer
+func (producer *Producer) Connect(conn *nats.Conn) {
+	producer.conn = conn
+}
+
+// Publish publishes the given message
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	return producer.conn.PublishMsg(MessageToMsg(message))
+}
+
+// Close awaits till all messages are published and flushes the NATS connection
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+
+	if producer.conn == nil {
+		return nil
+	}
+
+	return producer.conn.Flush()
+}
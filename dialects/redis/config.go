@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Default config value's
+var (
+	DefaultBlock         = time.Second
+	DefaultClaimInterval = 5 * time.Second
+	DefaultMinIdle       = 30 * time.Second
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Address       string
+	Database      int
+	Group         string
+	Consumer      string
+	Block         time.Duration
+	ClaimInterval time.Duration
+	MinIdle       time.Duration
+	MaxLen        int64
+}
+
+// NewConfig constructs a Config from the given connection map.
+// If no consumer name is given is a unique consumer name generated.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Address:       values[AddressKey],
+		Group:         values[GroupKey],
+		Consumer:      values[ConsumerKey],
+		Block:         DefaultBlock,
+		ClaimInterval: DefaultClaimInterval,
+		MinIdle:       DefaultMinIdle,
+	}
+
+	if config.Consumer == "" {
+		config.Consumer = uuid.Must(uuid.NewV4()).String()
+	}
+
+	if values[DatabaseKey] != "" {
+		database, err := strconv.Atoi(values[DatabaseKey])
+		if err != nil {
+			return config, err
+		}
+
+		config.Database = database
+	}
+
+	if values[MaxLenKey] != "" {
+		length, err := strconv.ParseInt(values[MaxLenKey], 10, 64)
+		if err != nil {
+			return config, err
+		}
+
+		config.MaxLen = length
+	}
+
+	durations := map[string]*time.Duration{
+		BlockKey:         &config.Block,
+		ClaimIntervalKey: &config.ClaimInterval,
+		MinIdleKey:       &config.MinIdle,
+	}
+
+	for key, duration := range durations {
+		if values[key] == "" {
+			continue
+		}
+
+		value, err := time.ParseDuration(values[key])
+		if err != nil {
+			return config, err
+		}
+
+		*duration = value
+	}
+
+	return config, nil
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("address=%s db=%s group=%s consumer=%s block=%s claim-interval=%s min-idle=%s max-len=%s", val, val, val, val, val, val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		AddressKey,
+		DatabaseKey,
+		GroupKey,
+		ConsumerKey,
+		BlockKey,
+		ClaimIntervalKey,
+		MinIdleKey,
+		MaxLenKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestValidateConnectionKeyVal tests if the required connectionstring values are validated
+func TestValidateConnectionKeyVal(t *testing.T) {
+	err := ValidateConnectionKeyVal(ConnectionMap{GroupKey: "group"})
+	if err == nil {
+		t.Fatal("no error returned for a missing address")
+	}
+
+	err = ValidateConnectionKeyVal(ConnectionMap{AddressKey: "localhost:6379"})
+	if err == nil {
+		t.Fatal("no error returned for a missing group")
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		AddressKey:  "localhost:6379",
+		DatabaseKey: "2",
+		GroupKey:    "group",
+		MinIdleKey:  "1m",
+		MaxLenKey:   "1000",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Database != 2 {
+		t.Fatal("Database not set")
+	}
+
+	if conf.Consumer == "" {
+		t.Fatal("No consumer name generated")
+	}
+
+	if conf.MinIdle != time.Minute || conf.Block != DefaultBlock {
+		t.Fatal("Unexpected durations")
+	}
+
+	if conf.MaxLen != 1000 {
+		t.Fatal("Max length not set")
+	}
+}
+
+// TestNewConfigInvalidDuration tests if a error is returned for a invalid duration
+func TestNewConfigInvalidDuration(t *testing.T) {
+	_, err := NewConfig(ConnectionMap{BlockKey: "forever"})
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
@@ -0,0 +1,293 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Topic represents a thread safe list of subscriptions
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	mutex         sync.RWMutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+	}
+}
+
+// NewConsumer constructs a new Redis streams consumer
+func NewConsumer(config Config) *Consumer {
+	return &Consumer{
+		config: config,
+		topics: make(map[string]*Topic),
+	}
+}
+
+// Consumer consumes Redis stream entries through a consumer group.
+// Acknowledged entries are acknowledged (XACK) inside the consumer group, negative acknowledged
+// entries remain pending and are claimed and redelivered once they have been idle for the configured
+// minimum idle time. Pending entries of consumers that have died are claimed in the same manner.
+// Entries read while no subscriptions are made remain pending and are claimed once a subscription is made.
+type Consumer struct {
+	client   *redis.Client
+	config   Config
+	topics   map[string]*Topic
+	streams  []string
+	cancel   context.CancelFunc
+	loops    sync.WaitGroup
+	claiming sync.Mutex
+	mutex    sync.RWMutex
+}
+
+// Connect creates the consumer group for the given topics and starts consuming and claiming stream entries
+func (consumer *Consumer) Connect(client *redis.Client, topics ...types.Topic) error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	consumer.client = client
+	ctx, cancel := context.WithCancel(context.Background())
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		err := client.XGroupCreateMkStream(ctx, topic.Name(), consumer.config.Group, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			cancel()
+			return err
+		}
+
+		consumer.streams = append(consumer.streams, topic.Name())
+	}
+
+	consumer.cancel = cancel
+
+	if len(consumer.streams) == 0 {
+		return nil
+	}
+
+	consumer.loops.Add(2)
+	go consumer.Read(ctx)
+	go consumer.Reclaim(ctx)
+
+	return nil
+}
+
+// Read reads new stream entries for the consumer group till the given context is done
+func (consumer *Consumer) Read(ctx context.Context) {
+	defer consumer.loops.Done()
+
+	streams := make([]string, 0, len(consumer.streams)*2)
+	streams = append(streams, consumer.streams...)
+	for range consumer.streams {
+		streams = append(streams, ">")
+	}
+
+	for ctx.Err() == nil {
+		results, err := consumer.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    consumer.config.Group,
+			Consumer: consumer.config.Consumer,
+			Streams:  streams,
+			Block:    consumer.config.Block,
+		}).Result()
+
+		if err == redis.Nil || ctx.Err() != nil {
+			continue
+		}
+
+		if err != nil {
+			log.Error(err)
+			time.Sleep(consumer.config.Block)
+			continue
+		}
+
+		for _, stream := range results {
+			for _, entry := range stream.Messages {
+				consumer.Claim(ctx, stream.Stream, entry)
+			}
+		}
+	}
+}
+
+// Reclaim periodically claims stream entries that have been pending for longer than the configured
+// minimum idle time. Claimed entries are redelivered to the subscriptions.
+func (consumer *Consumer) Reclaim(ctx context.Context) {
+	defer consumer.loops.Done()
+
+	ticker := time.NewTicker(consumer.config.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, stream := range consumer.streams {
+			consumer.ClaimPending(ctx, stream)
+		}
+	}
+}
+
+// ClaimPending claims all entries of the given stream that have been pending for longer than the configured
+// minimum idle time. Entries are claimed in batches, the cursor returned by Redis is followed till all
+// pending entries have been scanned.
+func (consumer *Consumer) ClaimPending(ctx context.Context, stream string) {
+	start := "0-0"
+
+	for {
+		entries, next, err := consumer.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    consumer.config.Group,
+			Consumer: consumer.config.Consumer,
+			MinIdle:  consumer.config.MinIdle,
+			Start:    start,
+		}).Result()
+
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error(err)
+			}
+
+			return
+		}
+
+		for _, entry := range entries {
+			consumer.Claim(ctx, stream, entry)
+		}
+
+		if next == "0-0" || ctx.Err() != nil {
+			return
+		}
+
+		start = next
+	}
+}
+
+// Claim emit's the given stream entry to the subscribed subscriptions. All subscriptions are
+// awaited untill done. The entry is acknowledged inside the consumer group if all subscriptions
+// acknowledged the message, the entry remains pending if the message got negative acknowledged
+// or if no subscriptions are made. Entries read and reclaimed are claimed one at a time.
+func (consumer *Consumer) Claim(ctx context.Context, stream string, entry redis.XMessage) {
+	consumer.claiming.Lock()
+	defer consumer.claiming.Unlock()
+
+	consumer.mutex.RLock()
+	topic := consumer.topics[stream]
+	consumer.mutex.RUnlock()
+
+	if topic == nil {
+		return
+	}
+
+	message := MessageFromEntry(topic.topic, entry)
+
+	topic.mutex.RLock()
+	if len(topic.subscriptions) == 0 {
+		topic.mutex.RUnlock()
+		return
+	}
+
+	for _, subscription := range topic.subscriptions {
+		message.Reset()
+
+		select {
+		case subscription.messages <- message:
+		case <-ctx.Done():
+			topic.mutex.RUnlock()
+			return
+		}
+
+		err := message.Finally()
+		if err != nil {
+			topic.mutex.RUnlock()
+			return
+		}
+	}
+	topic.mutex.RUnlock()
+
+	err := consumer.client.XAck(ctx, stream, consumer.config.Group, entry.ID).Err()
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		collection := consumer.topics[topic.Name()]
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Close stops consuming and claiming stream entries and awaits till all claimed entries are processed
+func (consumer *Consumer) Close() error {
+	if consumer.cancel != nil {
+		consumer.cancel()
+	}
+
+	consumer.loops.Wait()
+	return nil
+}
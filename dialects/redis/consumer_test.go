@@ -0,0 +1,422 @@
+package redis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestConsumerConsumption tests if produced messages are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case consumed := <-messages:
+		defer consumed.Ack()
+
+		if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+			t.Fatal("message id, action or version not preserved")
+		}
+
+		if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+			t.Fatal("message key or data not preserved")
+		}
+
+		if !consumed.EOS || consumed.Status != types.StatusNotFound {
+			t.Fatal("message eos or status not preserved")
+		}
+
+		if consumed.Topic != topic {
+			t.Fatal("unexpected message topic")
+		}
+
+		id, has := metadata.ParentIDFromContext(consumed.Ctx())
+		if !has || string(id) != parent.ID {
+			t.Fatal("parent id not preserved")
+		}
+
+		entry, has := EntryFromContext(consumed.Ctx())
+		if !has || entry.Stream != topic.Name() || entry.ID == "" {
+			t.Fatal("stream entry metadata not set")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout reached")
+	}
+}
+
+// TestConsumerAck tests if acknowledged messages are removed from the pending entries list
+func TestConsumerAck(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := <-messages
+	consumed.Ack()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		pending, err := client.XPending(context.Background(), topic.Name(), "mock").Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pending.Count == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("acknowledged entry is still pending")
+}
+
+// TestConsumerNackRedelivery tests if negative acknowledged messages are claimed and redelivered
+func TestConsumerNackRedelivery(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock claim-interval=20ms min-idle=10ms", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := <-messages
+	consumed.Nack()
+
+	select {
+	case redelivered := <-messages:
+		defer redelivered.Ack()
+
+		if redelivered.ID != message.ID {
+			t.Fatal("unexpected message redelivered")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("negative acknowledged message not redelivered")
+	}
+}
+
+// TestConsumerLateSubscription tests if entries read before a subscription is made remain pending and are claimed once subscribed
+func TestConsumerLateSubscription(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock claim-interval=20ms min-idle=10ms", topic)
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err := dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case consumed := <-messages:
+		defer consumed.Ack()
+
+		if consumed.ID != message.ID {
+			t.Fatal("unexpected message consumed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("entry read before the subscription was made not delivered")
+	}
+}
+
+// TestConsumerCloseUnreceived tests if the consumer is able to close while a subscription is not receiving messages
+func TestConsumerCloseUnreceived(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock", topic)
+
+	_, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		dialect.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("consumer close blocked by a unreceived message")
+	}
+}
+
+// TestConsumerClaimStalePending tests if entries left pending by a stopped consumer are claimed by a other consumer of the group
+func TestConsumerClaimStalePending(t *testing.T) {
+	server := miniredis.RunT(t)
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	err := client.XGroupCreateMkStream(ctx, topic.Name(), "mock", "$").Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := types.NewMessage("command", 1, nil, nil)
+	message.Topic = topic
+
+	err = client.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic.Name(),
+		Values: MessageToValues(message),
+	}).Err()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the entry with a consumer which never acknowledges the entry
+	err = client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "mock",
+		Consumer: "dead",
+		Streams:  []string{topic.Name(), ">"},
+	}).Err()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect := NewTestDialect(t, server, "group=mock claim-interval=20ms min-idle=10ms", topic)
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case claimed := <-messages:
+		defer claimed.Ack()
+
+		if claimed.ID != message.ID {
+			t.Fatal("unexpected message claimed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stale pending entry not claimed")
+	}
+}
+
+// TestConsumerGroupLoadBalancing tests if entries are load balanced between the consumers of a group
+func TestConsumerGroupLoadBalancing(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+	consumed := int32(0)
+
+	for i := 0; i < 2; i++ {
+		dialect := NewTestDialect(t, server, "group=mock", topic)
+		messages, err := dialect.Consumer().Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			for message := range messages {
+				atomic.AddInt32(&consumed, 1)
+				message.Ack()
+			}
+		}()
+	}
+
+	producer := NewTestDialect(t, server, "group=producer", types.NewTopic("commands", nil, types.CommandMessage, types.ProduceMode))
+
+	for i := 0; i < 10; i++ {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err := producer.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&consumed) < 10 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&consumed) != 10 {
+		t.Fatalf("unexpected amount of consumed messages: %d", consumed)
+	}
+}
+
+// TestConsumerUnsubscribe tests if the subscription channel is closed once unsubscribed
+func TestConsumerUnsubscribe(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, server, "group=mock", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect.Consumer().Unsubscribe(messages)
+
+	select {
+	case _, open := <-messages:
+		if open {
+			t.Fatal("unexpected message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+// TestConsumerClaimPendingBatches tests if all pending entries are claimed when exceeding a single claim batch
+func TestConsumerClaimPendingBatches(t *testing.T) {
+	server := miniredis.RunT(t)
+	topic := types.NewTopic("commands", nil, types.CommandMessage, types.DefaultMode)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	err := client.XGroupCreateMkStream(ctx, topic.Name(), "mock", "$").Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: Redis claims up to 100 entries per XAUTOCLAIM call by default
+	entries := 250
+
+	for i := 0; i < entries; i++ {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err = client.XAdd(ctx, &redis.XAddArgs{
+			Stream: topic.Name(),
+			Values: MessageToValues(message),
+		}).Err()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Read the entries with a consumer which never acknowledges the entries
+	err = client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "mock",
+		Consumer: "dead",
+		Streams:  []string{topic.Name(), ">"},
+	}).Err()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialect := NewTestDialect(t, server, "group=mock claim-interval=1h min-idle=1ms", topic)
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed := int32(0)
+	go func() {
+		for message := range messages {
+			atomic.AddInt32(&claimed, 1)
+			message.Ack()
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// NOTE: a single claim pass should follow the returned cursor beyond the first batch
+	dialect.consumer.ClaimPending(ctx, topic.Name())
+
+	if atomic.LoadInt32(&claimed) <= 100 {
+		t.Fatalf("pending entries beyond the first batch not claimed: %d", claimed)
+	}
+
+	// NOTE: miniredis treats the start of XAUTOCLAIM as exclusive, the entries at the returned cursors are claimed during the next pass
+	dialect.consumer.ClaimPending(ctx, topic.Name())
+
+	if atomic.LoadInt32(&claimed) != int32(entries) {
+		t.Fatalf("unexpected amount of claimed entries: %d", claimed)
+	}
+
+	pending, err := client.XPending(ctx, topic.Name(), "mock").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pending.Count != 0 {
+		t.Fatalf("unexpected amount of pending entries: %d", pending.Count)
+	}
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// Dialect represents the Redis streams dialect
+type Dialect struct {
+	Connection Config
+	Options    *redis.Options
+
+	client   *redis.Client
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new Redis streams dialect.
+// Topic names are used as stream keys. Messages are consumed through the consumer group
+// defined in the connectionstring, entries are load balanced between all consumers of the group.
+func NewDialect(connectionstring string) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := &Dialect{
+		Connection: connection,
+		Options: &redis.Options{
+			Addr: connection.Address,
+			DB:   connection.Database,
+		},
+		consumer: NewConsumer(connection),
+		producer: NewProducer(connection),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open opens a Redis client and starts consuming the given topics
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	client := redis.NewClient(dialect.Options)
+
+	err := client.Ping(context.Background()).Err()
+	if err != nil {
+		return err
+	}
+
+	dialect.client = client
+	dialect.producer.Connect(client)
+
+	err = dialect.consumer.Connect(client, topics...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the Redis consumers, producers and client
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	if dialect.client != nil {
+		return dialect.client.Close()
+	}
+
+	return nil
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	if dialect.client == nil {
+		return false
+	}
+
+	return dialect.client.Ping(context.Background()).Err() == nil
+}
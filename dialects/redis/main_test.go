@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestDialect constructs and opens a new Redis dialect for the given server, connectionstring options and topics
+func NewTestDialect(t *testing.T, server *miniredis.Miniredis, options string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect(fmt.Sprintf("address=%s block=50ms %s", server.Addr(), options))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new Redis dialect
+func TestNewDialect(t *testing.T) {
+	server := miniredis.RunT(t)
+	dialect := NewTestDialect(t, server, "group=mock")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dialect.Healthy() {
+		t.Fatal("closed dialect reported as healthy")
+	}
+}
+
+// TestNewDialectNoGroup tests if a error is returned when no consumer group is defined
+func TestNewDialectNoGroup(t *testing.T) {
+	_, err := NewDialect("address=localhost:6379")
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
+
+// TestProducerMaxLen tests if streams are trimmed to the configured max length
+func TestProducerMaxLen(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+	dialect := NewTestDialect(t, server, "group=mock max-len=5", topic)
+
+	for i := 0; i < 20; i++ {
+		message := types.NewMessage("event", 1, nil, nil)
+		message.Topic = topic
+
+		err := dialect.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := server.Stream(topic.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) > 5 {
+		t.Fatalf("stream not trimmed: %d entries", len(entries))
+	}
+}
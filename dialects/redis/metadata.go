@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis stream entry fields
+const (
+	FieldData = "data"
+)
+
+// MessageFromEntry constructs a commander message of the given Redis stream entry.
+// The commander metadata is extracted from the stream entry fields.
+func MessageFromEntry(topic types.Topic, entry redis.XMessage) *types.Message {
+	message := &types.Message{
+		Topic:     topic,
+		Status:    types.StatusOK,
+		Timestamp: time.Now(),
+	}
+
+	message.NewCtx(NewEntryContext(context.Background(), Entry{
+		Stream: topic.Name(),
+		ID:     entry.ID,
+	}))
+
+	kv := map[string]string{}
+	for field, value := range entry.Values {
+		value, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if field == FieldData {
+			message.Data = []byte(value)
+			continue
+		}
+
+		kv[field] = value
+	}
+
+	headers.Unmarshal(message, kv)
+
+	if message.Key == nil {
+		message.Key = []byte(message.ID)
+	}
+
+	return message
+}
+
+// MessageToValues constructs the Redis stream entry fields of the given commander message.
+// Redis streams have no notion of message keys, the message key is therefore included as a header field.
+func MessageToValues(message *types.Message) map[string]interface{} {
+	values := map[string]interface{}{
+		FieldData:         message.Data,
+		headers.HeaderKey: message.Key,
+	}
+
+	for key, value := range headers.Marshal(message) {
+		values[key] = value
+	}
+
+	return values
+}
+
+// CtxKey typed context key
+type CtxKey string
+
+func (k CtxKey) String() string {
+	return string(k)
+}
+
+const (
+	// CtxEntry represents the Redis stream entry context type
+	CtxEntry = CtxKey("redis-entry")
+)
+
+// Entry Redis stream entry metadata
+type Entry struct {
+	Stream string
+	ID     string
+}
+
+// NewEntryContext creates a new context with the given Redis stream entry metadata attached.
+// NewEntryContext will overwrite any previously-appended metadata.
+func NewEntryContext(ctx context.Context, entry Entry) context.Context {
+	return context.WithValue(ctx, CtxEntry, entry)
+}
+
+// EntryFromContext returns the Redis stream entry metadata in ctx if it exists
+func EntryFromContext(ctx context.Context) (entry Entry, ok bool) {
+	entry, ok = ctx.Value(CtxEntry).(Entry)
+	return
+}
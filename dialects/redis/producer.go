@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewProducer constructs a new Redis streams producer
+func NewProducer(config Config) *Producer {
+	return &Producer{
+		config: config,
+	}
+}
+
+// Producer appends messages to Redis streams
+type Producer struct {
+	client     *redis.Client
+	config     Config
+	production sync.WaitGroup
+}
+
+// Connect assigns the given Redis client to the producer
+func (producer *Producer) Connect(client *redis.Client) {
+	producer.client = client
+}
+
+// Publish appends the given message to the stream of the message topic (XADD).
+// If a maximum length is configured is the stream approximately trimmed to the given length.
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	return producer.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: message.Topic.Name(),
+		MaxLen: producer.config.MaxLen,
+		Approx: producer.config.MaxLen > 0,
+		Values: MessageToValues(message),
+	}).Err()
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
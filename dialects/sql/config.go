@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"strconv"
+	"time"
+)
+
+// Initial offset key values
+const (
+	OffsetNewest = "newest"
+	OffsetOldest = "oldest"
+)
+
+// Default config value's
+var (
+	DefaultMessagesTable     = "commander_messages"
+	DefaultOffsetsTable      = "commander_offsets"
+	DefaultPollInterval      = time.Second
+	DefaultBatchSize         = 100
+	DefaultRetentionInterval = time.Minute
+	DefaultVisibilityDelay   = time.Second
+)
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Driver            Driver
+	Group             string
+	MessagesTable     string
+	OffsetsTable      string
+	InitialOffset     string
+	PollInterval      time.Duration
+	VisibilityDelay   time.Duration
+	BatchSize         int
+	Retention         time.Duration
+	RetentionInterval time.Duration
+}
+
+// NewConfig constructs a Config from the given connection map.
+// A retention of zero disables the retention cleanup job.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Driver:            Drivers[values[DriverKey]],
+		Group:             values[GroupKey],
+		MessagesTable:     values[MessagesTableKey],
+		OffsetsTable:      values[OffsetsTableKey],
+		InitialOffset:     values[InitialOffsetKey],
+		PollInterval:      DefaultPollInterval,
+		VisibilityDelay:   DefaultVisibilityDelay,
+		BatchSize:         DefaultBatchSize,
+		RetentionInterval: DefaultRetentionInterval,
+	}
+
+	if config.MessagesTable == "" {
+		config.MessagesTable = DefaultMessagesTable
+	}
+
+	if config.OffsetsTable == "" {
+		config.OffsetsTable = DefaultOffsetsTable
+	}
+
+	switch config.InitialOffset {
+	case "":
+		config.InitialOffset = OffsetNewest
+	case OffsetNewest, OffsetOldest:
+	default:
+		return config, ErrInvalidInitialOffset
+	}
+
+	if values[BatchSizeKey] != "" {
+		size, err := strconv.Atoi(values[BatchSizeKey])
+		if err != nil {
+			return config, err
+		}
+
+		config.BatchSize = size
+	}
+
+	durations := map[string]*time.Duration{
+		PollIntervalKey:      &config.PollInterval,
+		VisibilityDelayKey:   &config.VisibilityDelay,
+		RetentionKey:         &config.Retention,
+		RetentionIntervalKey: &config.RetentionInterval,
+	}
+
+	for key, duration := range durations {
+		if values[key] == "" {
+			continue
+		}
+
+		value, err := time.ParseDuration(values[key])
+		if err != nil {
+			return config, err
+		}
+
+		*duration = value
+	}
+
+	return config, nil
+}
@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"errors"
+	"strings"
+)
+
+// ConnectionMap contains the connectionstring as a key/value map
+type ConnectionMap map[string]string
+
+// These const's contain the connection string keys to different values
+const (
+	DriverKey            = "driver"
+	GroupKey             = "group"
+	MessagesTableKey     = "messages-table"
+	OffsetsTableKey      = "offsets-table"
+	InitialOffsetKey     = "initial-offset"
+	PollIntervalKey      = "poll-interval"
+	VisibilityDelayKey   = "visibility-delay"
+	BatchSizeKey         = "batch-size"
+	RetentionKey         = "retention"
+	RetentionIntervalKey = "retention-interval"
+)
+
+// ParseConnectionstring parses the given connectionstring and returns a map with all key/values
+func ParseConnectionstring(connectionstring string) ConnectionMap {
+	var values = make(map[string]string)
+
+	pairs := strings.Split(connectionstring, " ")
+	for _, pair := range pairs {
+		keyval := strings.Split(pair, "=")
+		if len(keyval) > 2 || len(keyval) < 2 {
+			continue
+		}
+
+		key := keyval[0]
+		value := keyval[1]
+
+		values[key] = value
+	}
+
+	return values
+}
+
+// ValidateConnectionKeyVal validates if all required valyues are set in the given connectionmap
+func ValidateConnectionKeyVal(values ConnectionMap) error {
+	if len(values[DriverKey]) == 0 {
+		return errors.New("No driver is defined in the connectionstring")
+	}
+
+	if _, has := Drivers[values[DriverKey]]; !has {
+		return errors.New("Unsupported driver defined in the connectionstring")
+	}
+
+	if len(values[GroupKey]) == 0 {
+		return errors.New("No consumer group is defined in the connectionstring")
+	}
+
+	return nil
+}
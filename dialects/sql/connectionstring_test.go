@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("driver=%s group=%s messages-table=%s offsets-table=%s initial-offset=%s poll-interval=%s batch-size=%s retention=%s retention-interval=%s", val, val, val, val, val, val, val, val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		DriverKey,
+		GroupKey,
+		MessagesTableKey,
+		OffsetsTableKey,
+		InitialOffsetKey,
+		PollIntervalKey,
+		BatchSizeKey,
+		RetentionKey,
+		RetentionIntervalKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		DriverKey:    "postgres",
+		GroupKey:     "group",
+		RetentionKey: "24h",
+		BatchSizeKey: "10",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Driver.Bindvar != BindDollar {
+		t.Fatal("Driver not set")
+	}
+
+	if conf.MessagesTable != DefaultMessagesTable || conf.OffsetsTable != DefaultOffsetsTable {
+		t.Fatal("Default tables not set")
+	}
+
+	if conf.InitialOffset != OffsetNewest {
+		t.Fatal("Default initial offset not set")
+	}
+
+	if conf.Retention != 24*time.Hour || conf.RetentionInterval != DefaultRetentionInterval {
+		t.Fatal("Unexpected retention")
+	}
+
+	if conf.BatchSize != 10 {
+		t.Fatal("Batch size not set")
+	}
+}
+
+// TestNewConfigInvalidInitialOffset tests if a error is returned for a invalid initial offset
+func TestNewConfigInvalidInitialOffset(t *testing.T) {
+	_, err := NewConfig(ConnectionMap{InitialOffsetKey: "42"})
+	if err != ErrInvalidInitialOffset {
+		t.Fatal("unexpected error")
+	}
+}
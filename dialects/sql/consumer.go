@@ -0,0 +1,299 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Topic represents a thread safe list of subscriptions
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	notify        chan struct{}
+	mutex         sync.RWMutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+		notify:        make(chan struct{}, 1),
+	}
+}
+
+// NewConsumer constructs a new SQL consumer
+func NewConsumer(store *Store, config Config) *Consumer {
+	return &Consumer{
+		store:  store,
+		config: config,
+		topics: make(map[string]*Topic),
+	}
+}
+
+// Consumer polls the messages table for new messages of the consumed topics.
+// Messages are delivered in order of their position, the position of the consumer
+// group is committed once a message is acknowledged by all subscriptions. Negative
+// acknowledged messages are redelivered on the next poll.
+//
+// Positions are assigned when a message is inserted, concurrent transactions could however become
+// visible out of order. Messages are therefore only claimed once they have been stored for longer than
+// the configured visibility delay, lower positions are expected to be visible once the delay is reached.
+type Consumer struct {
+	store  *Store
+	config Config
+	topics map[string]*Topic
+	cancel context.CancelFunc
+	loops  sync.WaitGroup
+	mutex  sync.RWMutex
+}
+
+// Connect starts polling the given topics from the committed consumer group positions.
+// If no position has been committed is the configured initial offset used.
+func (consumer *Consumer) Connect(topics ...types.Topic) error {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.cancel = cancel
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		position, err := consumer.Position(ctx, topic.Name())
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		consumer.loops.Add(1)
+		go consumer.Poll(ctx, consumer.topics[topic.Name()], position)
+	}
+
+	return nil
+}
+
+// Position returns the position from which the given topic should be consumed
+func (consumer *Consumer) Position(ctx context.Context, topic string) (int64, error) {
+	position, committed, err := consumer.store.Committed(ctx, consumer.config.Group, topic)
+	if err != nil || committed {
+		return position, err
+	}
+
+	if consumer.config.InitialOffset == OffsetNewest {
+		position, err = consumer.store.Newest(ctx, topic)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// NOTE: the initial position is committed to make sure that messages stored
+	// before the first message is claimed are not skipped after a restart.
+	err = consumer.store.Commit(ctx, consumer.config.Group, topic, position)
+	return position, err
+}
+
+// Poll fetches and claims the messages of the given topic stored after the given position
+// till the given context is done. The topic is polled once the poll interval is reached, when the
+// consumer is notified about a newly produced message or when a fetched message passes the visibility delay.
+func (consumer *Consumer) Poll(ctx context.Context, topic *Topic, position int64) {
+	defer consumer.loops.Done()
+
+	for {
+		rows, err := consumer.store.Fetch(ctx, topic.topic.Name(), position, consumer.config.BatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Error(err)
+		}
+
+		visible := consumer.Visible(rows)
+
+		claimed := 0
+		for _, row := range visible {
+			if !consumer.Claim(ctx, topic, row) {
+				break
+			}
+
+			position = row.Position
+			claimed++
+		}
+
+		if len(rows) > 0 && claimed == len(rows) && len(rows) == consumer.config.BatchSize {
+			continue
+		}
+
+		interval := consumer.config.PollInterval
+		if claimed == len(visible) && len(visible) < len(rows) {
+			pending := time.Until(time.Unix(0, rows[len(visible)].CreatedAt).Add(consumer.config.VisibilityDelay))
+			if pending < interval {
+				interval = pending
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-topic.notify:
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Visible returns the leading rows that have been stored for longer than the visibility delay.
+// Rows following a row that is not yet visible are excluded to preserve the position order.
+func (consumer *Consumer) Visible(rows []Row) []Row {
+	horizon := time.Now().Add(-consumer.config.VisibilityDelay).UnixNano()
+
+	for index, row := range rows {
+		if row.CreatedAt > horizon {
+			return rows[:index]
+		}
+	}
+
+	return rows
+}
+
+// Claim emit's the given row as message to the subscriptions of the given topic. All subscriptions are
+// awaited untill done. The position is committed once all subscriptions acknowledged the message.
+// Rows are not claimed while the topic has no subscriptions, the poller is notified once a subscription is made.
+// Rows that could not be decoded into a message are logged and skipped.
+// A boolean is returned representing if the message has been committed.
+func (consumer *Consumer) Claim(ctx context.Context, topic *Topic, row Row) bool {
+	topic.mutex.RLock()
+	if len(topic.subscriptions) == 0 {
+		topic.mutex.RUnlock()
+		return false
+	}
+
+	message, err := row.Message(topic.topic)
+	if err != nil {
+		topic.mutex.RUnlock()
+		log.Errorf("skipping undecodable message at position %d of topic %s: %s", row.Position, row.Topic, err)
+		return consumer.Commit(ctx, topic, row)
+	}
+
+	for _, subscription := range topic.subscriptions {
+		message.Reset()
+
+		select {
+		case subscription.messages <- message:
+		case <-ctx.Done():
+			topic.mutex.RUnlock()
+			return false
+		}
+
+		err := message.Finally()
+		if err != nil {
+			topic.mutex.RUnlock()
+			return false
+		}
+	}
+	topic.mutex.RUnlock()
+
+	return consumer.Commit(ctx, topic, row)
+}
+
+// Commit commits the position of the given row for the consumer group.
+// A boolean is returned representing if the position has been committed.
+func (consumer *Consumer) Commit(ctx context.Context, topic *Topic, row Row) bool {
+	err := consumer.store.Commit(ctx, consumer.config.Group, topic.topic.Name(), row.Position)
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// Notify notifies the poller of the given topic that a new message has been stored
+func (consumer *Consumer) Notify(topic string) {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	collection, has := consumer.topics[topic]
+	if !has {
+		return
+	}
+
+	select {
+	case collection.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		collection := consumer.topics[topic.Name()]
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+
+		select {
+		case collection.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Close stops polling and awaits till all claimed messages are processed
+func (consumer *Consumer) Close() error {
+	if consumer.cancel != nil {
+		consumer.cancel()
+	}
+
+	consumer.loops.Wait()
+	return nil
+}
@@ -0,0 +1,310 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Publish publishes the given amount of messages to the given topic
+func Publish(t *testing.T, dialect *Dialect, topic types.Topic, amount int) []*types.Message {
+	messages := make([]*types.Message, amount)
+
+	for i := 0; i < amount; i++ {
+		message := types.NewMessage("event", 1, nil, nil)
+		message.Topic = topic
+
+		err := dialect.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		messages[i] = message
+	}
+
+	return messages
+}
+
+// TestConsumerConsumption tests if produced messages are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	db := NewTestDatabase(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, db, "group=mock poll-interval=1h", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	err = dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+		t.Fatal("message key or data not preserved")
+	}
+
+	if !consumed.EOS || consumed.Status != types.StatusNotFound {
+		t.Fatal("message eos or status not preserved")
+	}
+
+	if consumed.Topic != topic {
+		t.Fatal("unexpected message topic")
+	}
+
+	id, has := metadata.ParentIDFromContext(consumed.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+
+	position, has := PositionFromContext(consumed.Ctx())
+	if !has || position.Topic != topic.Name() || position.Offset == 0 {
+		t.Fatal("message position not set")
+	}
+}
+
+// TestConsumerNackRedelivery tests if negative acknowledged messages are redelivered in order
+func TestConsumerNackRedelivery(t *testing.T) {
+	db := NewTestDatabase(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, db, "group=mock", topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, dialect, topic, 2)
+
+	consumed := testutil.Await(t, messages)
+	if consumed.ID != published[0].ID {
+		t.Fatal("unexpected message consumed")
+	}
+
+	consumed.Nack()
+
+	for _, expected := range published {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("messages not redelivered in order")
+		}
+
+		consumed.Ack()
+	}
+}
+
+// TestConsumerCommittedOffset tests if a reopened consumer group resumes from its committed position
+func TestConsumerCommittedOffset(t *testing.T) {
+	db := NewTestDatabase(t)
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	first := NewTestDialect(t, db, "group=mock", topic)
+	messages, err := first.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, first, topic, 3)
+
+	testutil.Await(t, messages).Ack()
+	consumed := testutil.Await(t, messages)
+	consumed.Nack()
+
+	err = first.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewTestDialect(t, db, "group=mock", topic)
+	messages, err = second.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range published[1:] {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("consumer group did not resume from its committed position")
+		}
+
+		consumed.Ack()
+	}
+}
+
+// TestConsumerLateSubscription tests if messages stored before a subscription is made are held back
+// till the topic has a subscription, including messages stored before a restart
+func TestConsumerLateSubscription(t *testing.T) {
+	db := NewTestDatabase(t)
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	first := NewTestDialect(t, db, "group=mock", topic)
+	published := Publish(t, first, topic, 2)
+
+	time.Sleep(100 * time.Millisecond)
+
+	err := first.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewTestDialect(t, db, "group=mock", topic)
+	time.Sleep(100 * time.Millisecond)
+
+	messages, err := second.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range published {
+		consumed := testutil.Await(t, messages)
+		if consumed.ID != expected.ID {
+			t.Fatal("message consumed before the subscription was made")
+		}
+
+		consumed.Ack()
+	}
+}
+
+// TestConsumerUndecodableMessage tests if stored messages that could not be decoded are skipped
+func TestConsumerUndecodableMessage(t *testing.T) {
+	db := NewTestDatabase(t)
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	dialect := NewTestDialect(t, db, "group=mock", topic)
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("INSERT INTO "+DefaultMessagesTable+" (topic, message_key, headers, payload, created_at) VALUES (?, ?, ?, ?, ?)", topic.Name(), nil, "{malformed", nil, time.Now().UnixNano())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, dialect, topic, 1)
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != published[0].ID {
+		t.Fatal("unexpected message consumed")
+	}
+}
+
+// TestConsumerVisibilityDelay tests if stored messages are only consumed once the visibility delay has passed
+func TestConsumerVisibilityDelay(t *testing.T) {
+	db := NewTestDatabase(t)
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	dialect := NewTestDialect(t, db, "group=mock poll-interval=1h visibility-delay=200ms", topic)
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := Publish(t, dialect, topic, 1)
+
+	select {
+	case <-messages:
+		t.Fatal("message consumed before the visibility delay passed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	consumed := testutil.Await(t, messages)
+	defer consumed.Ack()
+
+	if consumed.ID != published[0].ID {
+		t.Fatal("unexpected message consumed")
+	}
+}
+
+// TestConsumerInitialOffset tests if a new consumer group starts at the configured initial offset
+func TestConsumerInitialOffset(t *testing.T) {
+	tests := map[string]struct {
+		offset   string
+		expected int
+	}{
+		"newest": {OffsetNewest, 1},
+		"oldest": {OffsetOldest, 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := NewTestDatabase(t)
+			topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+			producer := NewTestDialect(t, db, "group=producer")
+			Publish(t, producer, topic, 2)
+
+			dialect := NewTestDialect(t, db, "group=mock initial-offset="+test.offset, topic)
+			messages, err := dialect.Consumer().Subscribe(topic)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			Publish(t, producer, topic, 1)
+
+			for i := 0; i < test.expected; i++ {
+				testutil.Await(t, messages).Ack()
+			}
+
+			select {
+			case <-messages:
+				t.Fatal("unexpected message consumed")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
+
+// TestConsumerGroups tests if every consumer group consumes all messages
+func TestConsumerGroups(t *testing.T) {
+	db := NewTestDatabase(t)
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	groups := []string{"first", "second"}
+	subscriptions := []<-chan *types.Message{}
+
+	for _, group := range groups {
+		dialect := NewTestDialect(t, db, "group="+group, topic)
+		messages, err := dialect.Consumer().Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		subscriptions = append(subscriptions, messages)
+	}
+
+	producer := NewTestDialect(t, db, "group=producer")
+	published := Publish(t, producer, topic, 2)
+
+	for _, messages := range subscriptions {
+		for _, expected := range published {
+			consumed := testutil.Await(t, messages)
+			if consumed.ID != expected.ID {
+				t.Fatal("unexpected message consumed")
+			}
+
+			consumed.Ack()
+		}
+	}
+}
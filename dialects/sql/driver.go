@@ -0,0 +1,73 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Bindvar types used by the different SQL drivers
+const (
+	BindQuestion = iota
+	BindDollar
+)
+
+// Driver contains the SQL dialect specific statements of a database driver.
+// Statements are formatted with the messages table as first and the offsets table as second argument.
+type Driver struct {
+	Bindvar      int
+	Schema       []string
+	UpsertOffset string
+}
+
+// Drivers contains the supported database drivers by driver name
+var Drivers = map[string]Driver{
+	"sqlite3": {
+		Bindvar: BindQuestion,
+		Schema: []string{
+			"CREATE TABLE IF NOT EXISTS %[1]s (position INTEGER PRIMARY KEY AUTOINCREMENT, topic VARCHAR(255) NOT NULL, message_key BLOB, headers TEXT NOT NULL, payload BLOB, created_at BIGINT NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS %[1]s_topic ON %[1]s (topic, position)",
+			"CREATE TABLE IF NOT EXISTS %[2]s (consumer_group VARCHAR(255) NOT NULL, topic VARCHAR(255) NOT NULL, position BIGINT NOT NULL, PRIMARY KEY (consumer_group, topic))",
+		},
+		UpsertOffset: "INSERT INTO %[2]s (consumer_group, topic, position) VALUES (?, ?, ?) ON CONFLICT (consumer_group, topic) DO UPDATE SET position = excluded.position",
+	},
+	"postgres": {
+		Bindvar: BindDollar,
+		Schema: []string{
+			"CREATE TABLE IF NOT EXISTS %[1]s (position BIGSERIAL PRIMARY KEY, topic VARCHAR(255) NOT NULL, message_key BYTEA, headers TEXT NOT NULL, payload BYTEA, created_at BIGINT NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS %[1]s_topic ON %[1]s (topic, position)",
+			"CREATE TABLE IF NOT EXISTS %[2]s (consumer_group VARCHAR(255) NOT NULL, topic VARCHAR(255) NOT NULL, position BIGINT NOT NULL, PRIMARY KEY (consumer_group, topic))",
+		},
+		UpsertOffset: "INSERT INTO %[2]s (consumer_group, topic, position) VALUES (?, ?, ?) ON CONFLICT (consumer_group, topic) DO UPDATE SET position = excluded.position",
+	},
+	"mysql": {
+		Bindvar: BindQuestion,
+		Schema: []string{
+			"CREATE TABLE IF NOT EXISTS %[1]s (position BIGINT AUTO_INCREMENT PRIMARY KEY, topic VARCHAR(255) NOT NULL, message_key BLOB, headers TEXT NOT NULL, payload LONGBLOB, created_at BIGINT NOT NULL, INDEX (topic, position))",
+			"CREATE TABLE IF NOT EXISTS %[2]s (consumer_group VARCHAR(255) NOT NULL, topic VARCHAR(255) NOT NULL, position BIGINT NOT NULL, PRIMARY KEY (consumer_group, topic))",
+		},
+		UpsertOffset: "INSERT INTO %[2]s (consumer_group, topic, position) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE position = VALUES(position)",
+	},
+}
+
+// Rebind replaces the question mark placeholders inside the given query
+// with the bindvar type of the driver.
+func (driver Driver) Rebind(query string) string {
+	if driver.Bindvar == BindQuestion {
+		return query
+	}
+
+	result := strings.Builder{}
+	index := 0
+
+	for _, char := range query {
+		if char != '?' {
+			result.WriteRune(char)
+			continue
+		}
+
+		index++
+		result.WriteString("$" + strconv.Itoa(index))
+	}
+
+	return result.String()
+}
@@ -0,0 +1,17 @@
+package sql
+
+import "testing"
+
+// TestRebind tests if question mark placeholders are replaced with the driver bindvar type
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM messages WHERE topic = ? AND position > ?"
+
+	if Drivers["sqlite3"].Rebind(query) != query {
+		t.Fatal("unexpected sqlite3 query")
+	}
+
+	expected := "SELECT * FROM messages WHERE topic = $1 AND position > $2"
+	if Drivers["postgres"].Rebind(query) != expected {
+		t.Fatalf("unexpected postgres query: %s", Drivers["postgres"].Rebind(query))
+	}
+}
@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Dialect represents the SQL dialect
+type Dialect struct {
+	Connection Config
+
+	db        *sql.DB
+	store     *Store
+	consumer  *Consumer
+	producer  *Producer
+	retention *Retention
+}
+
+// NewDialect initializes and constructs a new SQL dialect for the given database.
+// Messages are stored inside a messages table and polled by consumers, messages produced
+// through the same dialect notify its consumers directly. The consumer group positions are
+// stored inside a offsets table. The database is expected to be opened and closed by the caller.
+func NewDialect(db *sql.DB, connectionstring string) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	err := ValidateConnectionKeyVal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewStore(db, connection)
+	consumer := NewConsumer(store, connection)
+
+	dialect := &Dialect{
+		Connection: connection,
+		db:         db,
+		store:      store,
+		consumer:   consumer,
+		producer:   NewProducer(store, consumer.Notify),
+		retention:  NewRetention(store, connection),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Open creates the messages and offsets tables if they do not exist
+// and starts consuming the given topics.
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	err := dialect.store.Migrate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = dialect.consumer.Connect(topics...)
+	if err != nil {
+		return err
+	}
+
+	dialect.retention.Start()
+	return nil
+}
+
+// Cleanup deletes all messages older than the configured retention.
+// The amount of deleted messages is returned.
+func (dialect *Dialect) Cleanup() (int64, error) {
+	return dialect.retention.Cleanup(context.Background())
+}
+
+// Close closes the SQL consumers, producers and retention cleanup job.
+// The database is not closed.
+func (dialect *Dialect) Close() error {
+	var err error
+
+	err = dialect.retention.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.consumer.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	return dialect.db.Ping() == nil
+}
@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewTestDatabase opens a new SQLite database inside a temporary directory.
+// The database is closed once the test is completed.
+func NewTestDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "commander.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// NewTestDialect constructs and opens a new SQL dialect for the given database, connectionstring options and topics
+func NewTestDialect(t *testing.T, db *sql.DB, options string, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect(db, "driver=sqlite3 poll-interval=50ms visibility-delay=10ms "+options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new SQL dialect
+func TestNewDialect(t *testing.T) {
+	db := NewTestDatabase(t)
+	dialect := NewTestDialect(t, db, "group=mock")
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNewDialectUnsupportedDriver tests if a error is returned for a unsupported driver
+func TestNewDialectUnsupportedDriver(t *testing.T) {
+	_, err := NewDialect(nil, "driver=oracle group=mock")
+	if err == nil {
+		t.Fatal("no error returned")
+	}
+}
+
+// TestRetentionCleanup tests if messages older than the configured retention are deleted
+func TestRetentionCleanup(t *testing.T) {
+	db := NewTestDatabase(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+	dialect := NewTestDialect(t, db, "group=mock retention=1ns retention-interval=1h", topic)
+
+	for i := 0; i < 5; i++ {
+		message := types.NewMessage("event", 1, nil, nil)
+		message.Topic = topic
+
+		err := dialect.Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := dialect.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted != 5 {
+		t.Fatalf("unexpected amount of deleted messages: %d", deleted)
+	}
+}
+
+// TestRetentionDisabled tests if no messages are deleted when no retention is configured
+func TestRetentionDisabled(t *testing.T) {
+	db := NewTestDatabase(t)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+	dialect := NewTestDialect(t, db, "group=mock", topic)
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err := dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := dialect.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted != 0 {
+		t.Fatalf("unexpected amount of deleted messages: %d", deleted)
+	}
+}
@@ -0,0 +1,47 @@
+package sql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewProducer constructs a new SQL producer. The given notify function is called
+// with the topic name once a message has been stored.
+func NewProducer(store *Store, notify func(topic string)) *Producer {
+	return &Producer{
+		store:  store,
+		notify: notify,
+	}
+}
+
+// Producer stores messages inside the messages table
+type Producer struct {
+	store      *Store
+	notify     func(topic string)
+	production sync.WaitGroup
+}
+
+// Publish stores the given message and notifies the consumers of the same dialect
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	err := producer.store.Insert(context.Background(), message)
+	if err != nil {
+		return err
+	}
+
+	if producer.notify != nil {
+		producer.notify(message.Topic.Name())
+	}
+
+	return nil
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
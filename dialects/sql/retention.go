@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NewRetention constructs a new retention cleanup job for the given store
+func NewRetention(store *Store, config Config) *Retention {
+	return &Retention{
+		store:  store,
+		config: config,
+	}
+}
+
+// Retention periodically deletes all messages older than the configured retention
+type Retention struct {
+	store  *Store
+	config Config
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// Start starts the retention cleanup job. The job is not started if no retention is configured.
+func (retention *Retention) Start() {
+	if retention.config.Retention <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retention.cancel = cancel
+	retention.done.Add(1)
+
+	go func() {
+		defer retention.done.Done()
+
+		ticker := time.NewTicker(retention.config.RetentionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_, err := retention.Cleanup(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error(err)
+			}
+		}
+	}()
+}
+
+// Cleanup deletes all messages older than the configured retention.
+// The amount of deleted messages is returned. No messages are deleted if no retention is configured.
+func (retention *Retention) Cleanup(ctx context.Context) (int64, error) {
+	if retention.config.Retention <= 0 {
+		return 0, nil
+	}
+
+	return retention.store.Cleanup(ctx, time.Now().Add(-retention.config.Retention))
+}
+
+// Close stops the retention cleanup job
+func (retention *Retention) Close() error {
+	if retention.cancel != nil {
+		retention.cancel()
+	}
+
+	retention.done.Wait()
+	return nil
+}
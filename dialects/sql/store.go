@@ -0,0 +1,164 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Custom error types
+var (
+	ErrInvalidInitialOffset = errors.New("invalid initial offset, expected newest or oldest")
+)
+
+// Row represents a stored message row
+type Row struct {
+	Position  int64
+	Topic     string
+	Key       []byte
+	Headers   string
+	Payload   []byte
+	CreatedAt int64
+}
+
+// Message constructs a commander message of the stored row for the given topic
+func (row Row) Message(topic types.Topic) (*types.Message, error) {
+	message := &types.Message{
+		Topic:     topic,
+		Key:       row.Key,
+		Data:      row.Payload,
+		Status:    types.StatusOK,
+		Timestamp: time.Unix(0, row.CreatedAt),
+	}
+
+	message.NewCtx(NewPositionContext(context.Background(), Position{
+		Topic:  row.Topic,
+		Offset: row.Position,
+	}))
+
+	kv := map[string]string{}
+	err := json.Unmarshal([]byte(row.Headers), &kv)
+	if err != nil {
+		return nil, err
+	}
+
+	headers.Unmarshal(message, kv)
+	return message, nil
+}
+
+// NewStore constructs a new message store for the given database and config
+func NewStore(db *sql.DB, config Config) *Store {
+	return &Store{
+		db:     db,
+		config: config,
+	}
+}
+
+// Store executes the message and offset queries against the database
+type Store struct {
+	db     *sql.DB
+	config Config
+}
+
+// query formats and rebinds the given query for the configured tables and driver
+func (store *Store) query(query string) string {
+	return store.config.Driver.Rebind(fmt.Sprintf(query, store.config.MessagesTable, store.config.OffsetsTable))
+}
+
+// Migrate creates the messages and offsets tables if they do not exist
+func (store *Store) Migrate(ctx context.Context) error {
+	for _, statement := range store.config.Driver.Schema {
+		_, err := store.db.ExecContext(ctx, store.query(statement))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Insert stores the given message inside the messages table
+func (store *Store) Insert(ctx context.Context, message *types.Message) error {
+	bb, err := json.Marshal(headers.Marshal(message))
+	if err != nil {
+		return err
+	}
+
+	query := store.query("INSERT INTO %[1]s (topic, message_key, headers, payload, created_at) VALUES (?, ?, ?, ?, ?)")
+	_, err = store.db.ExecContext(ctx, query, message.Topic.Name(), []byte(message.Key), string(bb), message.Data, time.Now().UnixNano())
+	return err
+}
+
+// Fetch returns the messages of the given topic stored after the given position.
+// The messages are ordered by their position and limited to the given limit.
+func (store *Store) Fetch(ctx context.Context, topic string, after int64, limit int) ([]Row, error) {
+	query := store.query("SELECT position, topic, message_key, headers, payload, created_at FROM %[1]s WHERE topic = ? AND position > ? ORDER BY position LIMIT ?")
+	rows, err := store.db.QueryContext(ctx, query, topic, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := []Row{}
+	for rows.Next() {
+		row := Row{}
+		err := rows.Scan(&row.Position, &row.Topic, &row.Key, &row.Headers, &row.Payload, &row.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// Newest returns the position of the newest stored message of the given topic
+func (store *Store) Newest(ctx context.Context, topic string) (int64, error) {
+	var position int64
+	query := store.query("SELECT COALESCE(MAX(position), 0) FROM %[1]s WHERE topic = ?")
+	err := store.db.QueryRowContext(ctx, query, topic).Scan(&position)
+	return position, err
+}
+
+// Committed returns the committed position of the given consumer group and topic.
+// A boolean is returned representing if a position has been committed.
+func (store *Store) Committed(ctx context.Context, group string, topic string) (int64, bool, error) {
+	var position int64
+	query := store.query("SELECT position FROM %[2]s WHERE consumer_group = ? AND topic = ?")
+	err := store.db.QueryRowContext(ctx, query, group, topic).Scan(&position)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return position, true, nil
+}
+
+// Commit commits the given position for the given consumer group and topic
+func (store *Store) Commit(ctx context.Context, group string, topic string, position int64) error {
+	_, err := store.db.ExecContext(ctx, store.query(store.config.Driver.UpsertOffset), group, topic, position)
+	return err
+}
+
+// Cleanup deletes all messages stored before the given time.
+// The amount of deleted messages is returned.
+func (store *Store) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	query := store.query("DELETE FROM %[1]s WHERE created_at < ?")
+	result, err := store.db.ExecContext(ctx, query, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"time"
+)
+
+// Default config value's
+var (
+	DefaultTimeout = 5 * time.Second
+)
+
+// Endpoints contains the webhook endpoint URL's by topic name
+type Endpoints map[string]string
+
+// Config contains all the plausible configuration options
+type Config struct {
+	Address string
+	Timeout time.Duration
+}
+
+// NewConfig constructs a Config from the given connection map.
+// If a address is given is a HTTP server started serving the dialect handler.
+func NewConfig(values ConnectionMap) (Config, error) {
+	config := Config{
+		Address: values[AddressKey],
+		Timeout: DefaultTimeout,
+	}
+
+	if values[TimeoutKey] != "" {
+		timeout, err := time.ParseDuration(values[TimeoutKey])
+		if err != nil {
+			return config, err
+		}
+
+		config.Timeout = timeout
+	}
+
+	return config, nil
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParsingConnectionstring tests if able to parse a connectionstring
+func TestParsingConnectionstring(t *testing.T) {
+	val := "val"
+	str := fmt.Sprintf("address=%s timeout=%s", val, val)
+
+	values := ParseConnectionstring(str)
+	keys := []string{
+		AddressKey,
+		TimeoutKey,
+	}
+
+	for _, key := range keys {
+		if values[key] != val {
+			t.Fatalf("Key value not set: %s", key)
+		}
+	}
+}
+
+// TestNewConfig tests if able to create a new config of the given values
+func TestNewConfig(t *testing.T) {
+	values := ConnectionMap{
+		AddressKey: ":8080",
+		TimeoutKey: "1s",
+	}
+
+	conf, err := NewConfig(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Address != ":8080" {
+		t.Fatal("Address not set")
+	}
+
+	if conf.Timeout != time.Second {
+		t.Fatal("Unexpected timeout")
+	}
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Subscription represents a consumer topic(s) subscription
+type Subscription struct {
+	messages chan *types.Message
+}
+
+// Topic represents a thread safe list of subscriptions
+type Topic struct {
+	topic         types.Topic
+	subscriptions map[<-chan *types.Message]*Subscription
+	mutex         sync.RWMutex
+}
+
+// NewTopic constructs and returns a new Topic struct
+func NewTopic(topic types.Topic) *Topic {
+	return &Topic{
+		topic:         topic,
+		subscriptions: make(map[<-chan *types.Message]*Subscription),
+	}
+}
+
+// NewConsumer constructs a new webhook consumer
+func NewConsumer() *Consumer {
+	return &Consumer{
+		topics: make(map[string]*Topic),
+	}
+}
+
+// Consumer represents a http.Handler turning incoming webhook requests into messages.
+// Requests are expected to be POST'ed to the path of the topic name (ex: /events).
+// A 200 OK is responded once all subscriptions acknowledged the message, a 500 Internal
+// Server Error is responded once the message got negative acknowledged. A 404 Not Found is responded
+// for unknown topics and a 503 Service Unavailable if no subscriptions are made for the topic, allowing the sender to retry.
+type Consumer struct {
+	topics map[string]*Topic
+	mutex  sync.RWMutex
+}
+
+// Connect registers the given topics to be consumed
+func (consumer *Consumer) Connect(topics ...types.Topic) {
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if !topic.HasMode(types.ConsumeMode) {
+			continue
+		}
+
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+	}
+}
+
+// ServeHTTP handles incoming webhook requests
+func (consumer *Consumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	consumer.mutex.RLock()
+	topic, has := consumer.topics[strings.TrimPrefix(r.URL.Path, "/")]
+	consumer.mutex.RUnlock()
+
+	if !has || !topic.topic.HasMode(types.ConsumeMode) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	message, err := MessageFromRequest(topic.topic, r)
+	if err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	topic.mutex.RLock()
+	defer topic.mutex.RUnlock()
+
+	if len(topic.subscriptions) == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, subscription := range topic.subscriptions {
+		message.Reset()
+
+		select {
+		case subscription.messages <- message:
+		case <-r.Context().Done():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		err := message.Finally()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Subscribe subscribes to the given topics and returns a message channel
+func (consumer *Consumer) Subscribe(topics ...types.Topic) (<-chan *types.Message, error) {
+	subscription := &Subscription{
+		messages: make(chan *types.Message, 0),
+	}
+
+	consumer.mutex.Lock()
+	defer consumer.mutex.Unlock()
+
+	for _, topic := range topics {
+		if consumer.topics[topic.Name()] == nil {
+			consumer.topics[topic.Name()] = NewTopic(topic)
+		}
+
+		collection := consumer.topics[topic.Name()]
+		collection.mutex.Lock()
+		collection.subscriptions[subscription.messages] = subscription
+		collection.mutex.Unlock()
+	}
+
+	return subscription.messages, nil
+}
+
+// Unsubscribe removes the given channel from the available subscriptions.
+// A new goroutine is spawned to avoid locking the channel.
+func (consumer *Consumer) Unsubscribe(sub <-chan *types.Message) error {
+	consumer.mutex.RLock()
+	defer consumer.mutex.RUnlock()
+
+	closing := sync.Once{}
+
+	for _, topic := range consumer.topics {
+		go func(topic *Topic) {
+			topic.mutex.Lock()
+			subscription, has := topic.subscriptions[sub]
+			if has {
+				delete(topic.subscriptions, sub)
+				closing.Do(func() {
+					close(subscription.messages)
+				})
+			}
+			topic.mutex.Unlock()
+		}(topic)
+	}
+
+	return nil
+}
+
+// Close closes the webhook consumer
+func (consumer *Consumer) Close() error {
+	return nil
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewWebhookRequest constructs a new webhook request for the given topic
+func NewWebhookRequest(t *testing.T, topic types.Topic, message *types.Message) *http.Request {
+	r, err := NewRequest(context.Background(), "/"+topic.Name(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+// TestConsumerConsumption tests if incoming webhook requests are consumed including their metadata
+func TestConsumerConsumption(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.NewMessage("command", 1, nil, nil)
+	message := parent.NewMessage("event", 2, []byte("key"), []byte("data"))
+	message.Topic = topic
+	message.EOS = true
+	message.Status = types.StatusNotFound
+
+	go func() {
+		consumed := <-messages
+		defer consumed.Ack()
+
+		if consumed.ID != message.ID || consumed.Action != message.Action || consumed.Version != message.Version {
+			t.Error("message id, action or version not preserved")
+		}
+
+		if string(consumed.Key) != "key" || string(consumed.Data) != "data" {
+			t.Error("message key or data not preserved")
+		}
+
+		if !consumed.EOS || consumed.Status != types.StatusNotFound {
+			t.Error("message eos or status not preserved")
+		}
+
+		id, has := metadata.ParentIDFromContext(consumed.Ctx())
+		if !has || string(id) != parent.ID {
+			t.Error("parent id not preserved")
+		}
+
+		_, has = metadata.HeaderFromContext(consumed.Ctx())
+		if has {
+			t.Error("transport headers included as message headers")
+		}
+	}()
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, NewWebhookRequest(t, topic, message))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerNack tests if negative acknowledged messages are responded with a 5xx status code
+func TestConsumerNack(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		message := <-messages
+		message.Nack()
+	}()
+
+	message := types.NewMessage("event", 1, nil, nil)
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, NewWebhookRequest(t, topic, message))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerUnknownTopic tests if requests for unknown topics are responded with a 404 status code
+func TestConsumerUnknownTopic(t *testing.T) {
+	dialect := NewTestDialect(t, "", nil)
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/unknown", strings.NewReader("")))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerNoSubscriptions tests if requests for topics without subscriptions are responded with a 503 status code
+func TestConsumerNoSubscriptions(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	message := types.NewMessage("event", 1, nil, nil)
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, NewWebhookRequest(t, topic, message))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerMethodNotAllowed tests if non POST requests are rejected
+func TestConsumerMethodNotAllowed(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerCustomHeaders tests if custom request headers are included as message headers
+func TestConsumerCustomHeaders(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		message := <-messages
+		defer message.Ack()
+
+		kv, has := metadata.HeaderFromContext(message.Ctx())
+		if !has || kv["x-partner"].String() != "acme" {
+			t.Error("custom header not preserved")
+		}
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader("data"))
+	r.Header.Set(headers.HeaderID, "id")
+	r.Header.Set("X-Partner", "acme")
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
+
+// TestConsumerNoID tests if a message ID and key are generated for requests without a ID header
+func TestConsumerNoID(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+	dialect := NewTestDialect(t, "", nil, topic)
+
+	messages, err := dialect.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		message := <-messages
+		defer message.Ack()
+
+		if message.ID == "" {
+			t.Error("message id not generated")
+		}
+
+		if string(message.Key) != message.ID {
+			t.Error("message key not set to the generated id")
+		}
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader("data"))
+
+	recorder := httptest.NewRecorder()
+	dialect.Handler().ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", recorder.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Dialect represents the webhook dialect
+type Dialect struct {
+	Connection Config
+	Endpoints  Endpoints
+	Client     *http.Client
+
+	server   *http.Server
+	open     bool
+	mutex    sync.RWMutex
+	consumer *Consumer
+	producer *Producer
+}
+
+// NewDialect initializes and constructs a new webhook dialect.
+// Produced messages are POST'ed to the endpoint of the message topic with the commander metadata
+// included as HTTP headers. Incoming webhooks are consumed through the dialect Handler, if a address
+// is defined in the connectionstring is a HTTP server started serving the handler once opened.
+func NewDialect(connectionstring string, endpoints Endpoints) (*Dialect, error) {
+	values := ParseConnectionstring(connectionstring)
+	connection, err := NewConfig(values)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{}
+
+	dialect := &Dialect{
+		Connection: connection,
+		Endpoints:  endpoints,
+		Client:     client,
+		consumer:   NewConsumer(),
+		producer:   NewProducer(connection, endpoints, client),
+	}
+
+	return dialect, nil
+}
+
+// Consumer returns the dialect as consumer
+func (dialect *Dialect) Consumer() types.Consumer {
+	return dialect.consumer
+}
+
+// Producer returns the dialect as producer
+func (dialect *Dialect) Producer() types.Producer {
+	return dialect.producer
+}
+
+// Handler returns the http.Handler consuming incoming webhook requests.
+// Requests are expected to be POST'ed to the path of the topic name (ex: /events).
+func (dialect *Dialect) Handler() http.Handler {
+	return dialect.consumer
+}
+
+// Open registers the given topics to be consumed and starts the HTTP server if a address is configured
+func (dialect *Dialect) Open(topics []types.Topic) error {
+	dialect.mutex.Lock()
+	defer dialect.mutex.Unlock()
+
+	dialect.consumer.Connect(topics...)
+
+	if dialect.Connection.Address != "" {
+		listener, err := net.Listen("tcp", dialect.Connection.Address)
+		if err != nil {
+			return err
+		}
+
+		dialect.server = &http.Server{
+			Handler: dialect.Handler(),
+		}
+
+		go func() {
+			err := dialect.server.Serve(listener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error(err)
+			}
+		}()
+	}
+
+	dialect.open = true
+	return nil
+}
+
+// Close shuts down the HTTP server and awaits till all messages are published
+func (dialect *Dialect) Close() error {
+	dialect.mutex.Lock()
+	defer dialect.mutex.Unlock()
+
+	dialect.open = false
+
+	if dialect.server != nil {
+		err := dialect.server.Shutdown(context.Background())
+		if err != nil {
+			return err
+		}
+	}
+
+	err := dialect.producer.Close()
+	if err != nil {
+		return err
+	}
+
+	return dialect.consumer.Close()
+}
+
+// Healthy returns a boolean that reprisents if the dialect is healthy
+func (dialect *Dialect) Healthy() bool {
+	dialect.mutex.RLock()
+	defer dialect.mutex.RUnlock()
+
+	return dialect.open
+}
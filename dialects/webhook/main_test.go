@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestDialect constructs and opens a new webhook dialect for the given endpoints and topics
+func NewTestDialect(t *testing.T, connectionstring string, endpoints Endpoints, topics ...types.Topic) *Dialect {
+	dialect, err := NewDialect(connectionstring, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Open(t, dialect, topics...)
+	return dialect
+}
+
+// TestNewDialect tests if able to construct and open a new webhook dialect
+func TestNewDialect(t *testing.T) {
+	dialect := NewTestDialect(t, "address=127.0.0.1:0", nil)
+
+	if !dialect.Healthy() {
+		t.Fatal("dialect not healthy")
+	}
+
+	err := dialect.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dialect.Healthy() {
+		t.Fatal("closed dialect reported as healthy")
+	}
+}
+
+// TestDialectRoundTrip tests if messages produced by a webhook dialect are consumed by a other webhook dialect
+func TestDialectRoundTrip(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	consumer := NewTestDialect(t, "", nil, topic)
+	server := httptest.NewServer(consumer.Handler())
+	defer server.Close()
+
+	messages, err := consumer.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for message := range messages {
+			message.Ack()
+		}
+	}()
+
+	producer := NewTestDialect(t, "", Endpoints{topic.Name(): server.URL + "/" + topic.Name()})
+
+	message := types.NewMessage("event", 1, nil, []byte("data"))
+	message.Topic = topic
+
+	err = producer.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDialectContentType tests if the message content type is propagated as request content type
+func TestDialectContentType(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.DefaultMode)
+
+	consumer := NewTestDialect(t, "", nil, topic)
+	server := httptest.NewServer(consumer.Handler())
+	defer server.Close()
+
+	messages, err := consumer.Consumer().Subscribe(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contentTypes := make(chan string, 1)
+	go func() {
+		for message := range messages {
+			contentType, _ := metadata.ContentTypeFromContext(message.Ctx())
+			contentTypes <- contentType
+			message.Ack()
+		}
+	}()
+
+	producer := NewTestDialect(t, "", Endpoints{topic.Name(): server.URL + "/" + topic.Name()})
+
+	message := types.NewMessage("event", 1, nil, []byte(`{}`))
+	message.Topic = topic
+	message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), "application/json"))
+
+	err = producer.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType := <-contentTypes; contentType != "application/json" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// ContentType represents the content type of webhook request bodies without a content type header
+const ContentType = "application/octet-stream"
+
+// TransportHeaders contains the HTTP headers that are not included as message metadata
+var TransportHeaders = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Connection",
+	"Content-Length",
+	"Content-Type",
+	"User-Agent",
+}
+
+// MessageFromRequest constructs a commander message of the given HTTP request.
+// The commander metadata is extracted from the HTTP request headers, a message ID is generated
+// when the request has no ID header.
+func MessageFromRequest(topic types.Topic, r *http.Request) (*types.Message, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &types.Message{
+		Topic:     topic,
+		Data:      data,
+		Status:    types.StatusOK,
+		Timestamp: time.Now(),
+	}
+
+	message.NewCtx(context.Background())
+
+	contentType := r.Header.Get("Content-Type")
+
+	header := r.Header.Clone()
+	for _, key := range TransportHeaders {
+		header.Del(key)
+	}
+
+	kv := map[string]string{}
+	for key := range header {
+		kv[strings.ToLower(key)] = header.Get(key)
+	}
+
+	headers.Unmarshal(message, kv)
+
+	if contentType != "" && contentType != ContentType {
+		message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), contentType))
+	}
+
+	if message.ID == "" {
+		message.ID = uuid.Must(uuid.NewV4()).String()
+	}
+
+	if message.Key == nil {
+		message.Key = []byte(message.ID)
+	}
+
+	return message, nil
+}
+
+// NewRequest constructs a HTTP POST request of the given commander message for the given endpoint.
+// HTTP has no notion of message keys, the message key is therefore included as a header.
+// The message content type is used as the request content type.
+func NewRequest(ctx context.Context, endpoint string, message *types.Message) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message.Data))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers.Marshal(message) {
+		r.Header.Set(key, value)
+	}
+
+	contentType, has := metadata.ContentTypeFromContext(message.Ctx())
+	if !has {
+		contentType = ContentType
+	}
+
+	r.Header.Set(headers.HeaderKey, string(message.Key))
+	r.Header.Set("Content-Type", contentType)
+
+	return r, nil
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Custom error types
+var (
+	ErrNoEndpoint = errors.New("no webhook endpoint configured for the given topic")
+)
+
+// StatusError is returned when a webhook endpoint responds with a non 2xx status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("unexpected webhook response status: %d", err.StatusCode)
+}
+
+// Temporary returns a boolean representing if the request could be retried
+func (err *StatusError) Temporary() bool {
+	return err.StatusCode >= http.StatusInternalServerError || err.StatusCode == http.StatusTooManyRequests
+}
+
+// NewProducer constructs a new webhook producer
+func NewProducer(config Config, endpoints Endpoints, client *http.Client) *Producer {
+	return &Producer{
+		config:    config,
+		endpoints: endpoints,
+		client:    client,
+	}
+}
+
+// Producer POSTs messages to the webhook endpoint of the message topic
+type Producer struct {
+	config     Config
+	endpoints  Endpoints
+	client     *http.Client
+	production sync.WaitGroup
+}
+
+// Publish POSTs the given message to the endpoint of the message topic. Failed requests are
+// not retried by the producer, failed publications are retried by the commander group (Group.Retries).
+func (producer *Producer) Publish(message *types.Message) error {
+	producer.production.Add(1)
+	defer producer.production.Done()
+
+	endpoint, has := producer.endpoints[message.Topic.Name()]
+	if !has {
+		return ErrNoEndpoint
+	}
+
+	return producer.post(endpoint, message)
+}
+
+// post performs a single webhook request for the given message
+func (producer *Producer) post(endpoint string, message *types.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), producer.config.Timeout)
+	defer cancel()
+
+	r, err := NewRequest(ctx, endpoint, message)
+	if err != nil {
+		return err
+	}
+
+	res, err := producer.client.Do(r)
+	if err != nil {
+		return err
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &StatusError{StatusCode: res.StatusCode}
+	}
+
+	return nil
+}
+
+// Close awaits till all messages are published
+func (producer *Producer) Close() error {
+	producer.production.Wait()
+	return nil
+}
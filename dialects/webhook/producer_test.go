@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jeroenrinzema/commander"
+	"github.com/jeroenrinzema/commander/internal/headers"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// NewTestEndpoint starts a new HTTP server responding with the given status codes in order.
+// The last status code is repeated once all status codes have been responded.
+func NewTestEndpoint(t *testing.T, requests *int32, codes ...int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := int(atomic.AddInt32(requests, 1)) - 1
+		if attempt >= len(codes) {
+			attempt = len(codes) - 1
+		}
+
+		w.WriteHeader(codes[attempt])
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestProducerHeaders tests if messages are POST'ed with the commander metadata as HTTP headers
+func TestProducerHeaders(t *testing.T) {
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+
+	message := types.NewMessage("event", 1, []byte("key"), []byte("data"))
+	message.Topic = topic
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("unexpected request method")
+		}
+
+		if r.Header.Get(headers.HeaderID) != message.ID || r.Header.Get(headers.HeaderAction) != message.Action {
+			t.Error("message metadata not included as headers")
+		}
+
+		if r.Header.Get(headers.HeaderKey) != "key" {
+			t.Error("message key not included as header")
+		}
+	}))
+
+	defer server.Close()
+
+	dialect := NewTestDialect(t, "", Endpoints{topic.Name(): server.URL})
+
+	err := dialect.Producer().Publish(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestProducerStatusError tests if a status error is returned for non 2xx responses without retrying the request
+func TestProducerStatusError(t *testing.T) {
+	requests := int32(0)
+	server := NewTestEndpoint(t, &requests, http.StatusServiceUnavailable)
+
+	topic := types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+	dialect := NewTestDialect(t, "", Endpoints{topic.Name(): server.URL})
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = topic
+
+	err := dialect.Producer().Publish(message)
+	status, ok := err.(*StatusError)
+	if !ok || status.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests != 1 {
+		t.Fatalf("unexpected amount of requests: %d", requests)
+	}
+}
+
+// TestProducerGroupRetry tests if failed requests are only retried by the commander group
+func TestProducerGroupRetry(t *testing.T) {
+	requests := int32(0)
+	server := NewTestEndpoint(t, &requests, http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK)
+
+	dialect := NewTestDialect(t, "", Endpoints{"events": server.URL})
+	group := commander.NewGroup(
+		commander.NewTopic("events", dialect, commander.EventMessage, commander.ProduceMode),
+	)
+
+	group.Retries = 2
+
+	err := group.ProduceEvent(types.NewMessage("event", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 3 {
+		t.Fatalf("unexpected amount of requests: %d", requests)
+	}
+}
+
+// TestProducerNoEndpoint tests if a error is returned when no endpoint is configured for the message topic
+func TestProducerNoEndpoint(t *testing.T) {
+	dialect := NewTestDialect(t, "", Endpoints{})
+
+	message := types.NewMessage("event", 1, nil, nil)
+	message.Topic = types.NewTopic("events", nil, types.EventMessage, types.ProduceMode)
+
+	err := dialect.Producer().Publish(message)
+	if err != ErrNoEndpoint {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 var (
 	ErrNoTopic  = errors.New("no topic found")
 	ErrNoAction = errors.New("no action defined")
+	ErrNoCodec  = errors.New("no codec defined to decode the message schema")
 )
 
 // NewGroup initializes a new commander group.
@@ -26,11 +28,18 @@ func NewGroup(definitions ...options.GroupOption) *Group {
 	options := options.NewGroupOptions(definitions)
 
 	group := &Group{
-		Timeout: options.Timeout,
-		Retries: options.Retries,
-		Topics:  options.Topics,
-		Codec:   options.Codec,
-		logger:  log.New(),
+		Timeout:         options.Timeout,
+		Retries:         options.Retries,
+		Topics:          options.Topics,
+		Codec:           options.Codec,
+		Codecs:          options.Codecs,
+		Policy:          options.Policy,
+		Upcasters:       options.Upcasters,
+		VersionFallback: options.VersionFallback,
+		logger:          log.New(),
+		versions: &versions{
+			registrations: make(map[types.MessageType][]*registration),
+		},
 	}
 
 	// NOTE: possible creation of a "universal" logger interface that could easily be implemented.
@@ -47,12 +56,35 @@ func NewGroup(definitions ...options.GroupOption) *Group {
 // commands and events could be consumed and produced to. The amount of retries
 // attempted before a error is thrown could also be defined in a group.
 type Group struct {
-	Middleware middleware.UseImpl
-	Timeout    time.Duration
-	Topics     []types.Topic
-	Codec      options.Codec
-	Retries    int8
-	logger     *log.Logger
+	Middleware      middleware.UseImpl
+	Timeout         time.Duration
+	Topics          []types.Topic
+	Codec           options.Codec
+	Codecs          options.Codecs
+	Retries         int8
+	Policy          options.ProducePolicy
+	Upcasters       *types.Upcasters
+	VersionFallback types.HandlerFunc
+	logger          *log.Logger
+	versions        *versions
+	inflight        types.InFlight
+}
+
+// ProduceError is returned when a message could not be produced to one or multiple topics.
+// The failed topics and their errors are stored at the same index.
+type ProduceError struct {
+	Topics []types.Topic
+	Errors []error
+}
+
+// Error returns the failed topics and their errors as a string
+func (err *ProduceError) Error() string {
+	failed := make([]string, len(err.Topics))
+	for index, topic := range err.Topics {
+		failed[index] = topic.Name() + ": " + err.Errors[index].Error()
+	}
+
+	return "failed to produce to topics: " + strings.Join(failed, ", ")
 }
 
 // Close represents a closing method
@@ -205,68 +237,76 @@ func (group *Group) FetchTopics(t types.MessageType, m types.TopicMode) []types.
 	return topics
 }
 
-// ProduceCommand produce a message to the given group command topic.
+// ProduceCommand produce a message to the given group command topics.
 // A error is returned if anything went wrong in the process. If no command key is set will the command id be used.
+// The message is produced to all command produce topics according to the group produce policy.
 func (group *Group) ProduceCommand(message *Message) error {
+	return group.Produce(CommandMessage, message)
+}
+
+// ProduceEvent produces a event message to the set event topics.
+// A error is returned if anything went wrong in the process.
+// The message is produced to all event produce topics according to the group produce policy.
+func (group *Group) ProduceEvent(message *Message) error {
+	return group.Produce(EventMessage, message)
+}
+
+// Produce produces the given message to the produce topics of the given message type.
+// The given message is produced to the first (primary) topic, a copy of the message is produced to the other topics.
+// A ProduceError is returned when producing failed according to the group produce policy.
+func (group *Group) Produce(sort types.MessageType, message *Message) error {
+	return group.produce(sort, message, group.Policy)
+}
+
+// produce produces the given message to the produce topics of the given message type according to the given produce policy
+func (group *Group) produce(sort types.MessageType, message *Message, policy options.ProducePolicy) error {
 	if message.Key == nil {
 		message.Key = metadata.Key([]byte(message.ID))
 	}
 
-	topics := group.FetchTopics(CommandMessage, ProduceMode)
+	topics := group.FetchTopics(sort, ProduceMode)
 	if len(topics) == 0 {
 		return ErrNoTopic
 	}
 
-	// NOTE: Support for multiple produce topics?
-	// Possible, but error handling has to be easily handled when errors occures at one of the topics in the process of publishing
-	topic := topics[0]
-	message.Topic = topic
-
-	retry := Retry{
-		Amount: group.Retries,
+	if policy == ProducePrimary {
+		topics = topics[:1]
 	}
 
-	err := retry.Attempt(func() error {
-		return group.Publish(message)
-	})
+	failed := &ProduceError{}
 
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
+	for index, topic := range topics {
+		produce := message
+		if index > 0 {
+			produce = message.Copy()
+		}
 
-// ProduceEvent produces a event kafka message to the set event topic.
-// A error is returned if anything went wrong in the process.
-func (group *Group) ProduceEvent(message *Message) error {
-	if message.Key == nil {
-		message.Key = metadata.Key([]byte(message.ID))
-	}
+		produce.Topic = topic
 
-	topics := group.FetchTopics(EventMessage, ProduceMode)
-	if len(topics) == 0 {
-		return ErrNoTopic
-	}
+		retry := Retry{
+			Amount: group.Retries,
+		}
 
-	// NOTE: Support for multiple produce topics?
-	// Possible, but error handling has to be easily handled when errors occures at one of the topics in the process of publishing
-	topic := topics[0]
-	message.Topic = topic
+		err := retry.Attempt(func() error {
+			return group.Publish(produce)
+		})
 
-	retry := Retry{
-		Amount: group.Retries,
+		if err != nil {
+			failed.Topics = append(failed.Topics, topic)
+			failed.Errors = append(failed.Errors, err)
+		}
 	}
 
-	err := retry.Attempt(func() error {
-		return group.Publish(message)
-	})
+	if len(failed.Topics) == 0 {
+		return nil
+	}
 
-	if err != nil {
-		return err
+	if policy == ProduceBestEffort && len(failed.Topics) < len(topics) {
+		group.logger.Warn(failed)
+		return nil
 	}
 
-	return nil
+	return failed
 }
 
 // Publish publishes the given message to the group producer.
@@ -281,8 +321,8 @@ func (group *Group) Publish(message *Message) error {
 }
 
 // NewConsumer starts consuming events of topics from the same topic type.
-// All received messages are published over the returned messages channel.
-// All middleware subscriptions are called before consuming the message.
+// Topics are subscribed to through their own dialect, the messages of all subscriptions are merged
+// and published over the returned messages channel. All middleware subscriptions are called before consuming the message.
 // Once a message is consumed should the next function be called to mark a message successfully consumed.
 func (group *Group) NewConsumer(sort types.MessageType) (<-chan *types.Message, Close, error) {
 	group.logger.Debugf("new message consumer: %d", sort)
@@ -292,34 +332,49 @@ func (group *Group) NewConsumer(sort types.MessageType) (<-chan *types.Message,
 		return make(<-chan *Message, 0), func() {}, ErrNoTopic
 	}
 
-	// NOTE: support multiple topics for consumption?
-	topic := topics[0]
-
 	sink := make(chan *Message, 0)
-	messages, err := topic.Dialect().Consumer().Subscribe(topics...)
-	if err != nil {
-		close(sink)
+	subscriptions := make(map[<-chan *types.Message]types.Dialect)
 
-		return sink, func() {}, err
+	unsubscribe := func() {
+		for messages, dialect := range subscriptions {
+			dialect.Consumer().Unsubscribe(messages)
+		}
+	}
+
+	for dialect, topics := range groupTopicsByDialect(topics) {
+		messages, err := dialect.Consumer().Subscribe(topics...)
+		if err != nil {
+			unsubscribe()
+			close(sink)
+
+			return sink, func() {}, err
+		}
+
+		subscriptions[messages] = dialect
 	}
 
 	mutex := sync.Mutex{}
 	breaker := circuit.Breaker{}
 
-	go func() {
-		for message := range messages {
-			if !breaker.Safe() {
-				message.Ack()
-				return
+	for messages := range subscriptions {
+		go func(messages <-chan *types.Message) {
+			for message := range messages {
+				group.logger.Debug("message consumer consumed message")
+
+				// NOTE: the breaker is checked while holding the lock since the sink could be closed
+				// while a other subscription is publishing a message.
+				mutex.Lock()
+				if !breaker.Safe() {
+					mutex.Unlock()
+					message.Ack()
+					return
+				}
+
+				sink <- message
+				mutex.Unlock()
 			}
-
-			group.logger.Debug("message consumer consumed message")
-
-			mutex.Lock()
-			sink <- message
-			mutex.Unlock()
-		}
-	}()
+		}(messages)
+	}
 
 	closer := func() {
 		mutex.Lock()
@@ -332,7 +387,7 @@ func (group *Group) NewConsumer(sort types.MessageType) (<-chan *types.Message,
 		breaker.Open()
 		close(sink)
 
-		go topic.Dialect().Consumer().Unsubscribe(messages)
+		go unsubscribe()
 	}
 
 	return sink, closer, nil
@@ -364,6 +419,84 @@ func (group *Group) NewConsumerWithDeadline(timeout time.Duration, t types.Messa
 	return messages, closing, nil
 }
 
+// CodecFor returns the codec registered for the content type of the given message.
+// The group default codec is returned if the message has no content type or if no codec is registered for it.
+func (group *Group) CodecFor(message *Message) options.Codec {
+	contentType, has := metadata.ContentTypeFromContext(message.Ctx())
+	if !has {
+		return group.Codec
+	}
+
+	if codec, ok := group.Codec.(options.ContentTypeCodec); ok && codec.ContentType() == contentType {
+		return group.Codec
+	}
+
+	codec, has := group.Codecs[contentType]
+	if !has {
+		return group.Codec
+	}
+
+	return codec
+}
+
+// Decode decodes the data of the given message with the codec of the message content type and stores the result as the message schema.
+// Codecs implementing options.MessageCodec decode the message based on its action and version, otherwise is
+// the data decoded into the schema returned by the given schema method. The codec default schema is used if no
+// schema method is given. Decoding errors are stored on the message and could be retrieved through message.SchemaError().
+// A ErrNoCodec error is stored if a schema method is given but the message could only be decoded by the ignore codec.
+func (group *Group) Decode(message *Message, schema func() interface{}) {
+	codec := group.CodecFor(message)
+
+	if _, ignore := codec.(*options.IgnoreCodec); ignore && schema != nil {
+		message.NewSchema(nil)
+		message.NewSchemaError(ErrNoCodec)
+		return
+	}
+
+	if decoder, ok := codec.(options.MessageCodec); ok {
+		decoded, err := decoder.UnmarshalMessage(message)
+		message.NewSchema(decoded)
+		message.NewSchemaError(err)
+		return
+	}
+
+	if schema == nil {
+		schema = codec.Schema
+	}
+
+	decoded := schema()
+	err := codec.Unmarshal(message.Data, &decoded)
+	message.NewSchema(decoded)
+	message.NewSchemaError(err)
+}
+
+// Encode encodes the given value with the codec of the message content type and stores the result as the message data.
+// If the message has no content type is the group default codec used. Codecs implementing options.MessageMarshaler encode
+// the value based on the message action and version. The content type header of the message is set if the codec defines a content type.
+func (group *Group) Encode(message *Message, value interface{}) error {
+	codec := group.CodecFor(message)
+
+	if encoder, ok := codec.(options.MessageMarshaler); ok {
+		err := encoder.MarshalMessage(message, value)
+		if err != nil {
+			return err
+		}
+	} else {
+		data, err := codec.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		message.Data = data
+	}
+
+	if typed, ok := codec.(options.ContentTypeCodec); ok {
+		message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), typed.ContentType()))
+	}
+
+	return nil
+}
+
 // Handle awaits messages from the given MessageType and action.
 // Once a message is received is the callback method called with the received command.
 // The handle is closed once the consumer receives a close signal.
@@ -379,9 +512,6 @@ func (group *Group) HandleFunc(sort types.MessageType, action string, callback H
 		WithAction(action),
 		WithMessageType(sort),
 		WithCallback(callback),
-		WithMessageSchema(func() interface{} {
-			return group.Codec.Schema()
-		}),
 	)
 }
 
@@ -390,28 +520,90 @@ func (group *Group) HandleContext(definitions ...options.HandlerOption) (Close,
 	options := options.NewHandlerOptions(definitions)
 	group.logger.Debugf("setting up new consumer handle: %d, %s", options.MessageType, options.Action)
 
-	messages, closing, err := group.NewConsumer(options.MessageType)
+	messages, closer, err := group.NewConsumer(options.MessageType)
 	if err != nil {
 		return nil, err
 	}
 
+	deregister := group.register(options.MessageType, &registration{
+		owner: options,
+		matches: func(action string) bool {
+			return options.Action == "" || options.Action == action
+		},
+		accepts: func(message *Message) bool {
+			return options.Versions.Contains(message.Version)
+		},
+	})
+
+	closing := func() {
+		deregister()
+		closer()
+	}
+
+	dispatcher := newDispatcher(group, options)
+
 	go func() {
+		defer dispatcher.close()
+
 		for message := range messages {
 			if options.Action != "" && message.Action != options.Action {
 				message.Ack()
 				continue
 			}
 
-			schema := options.Schema()
-			group.Codec.Unmarshal(message.Data, &schema)
-			message.NewSchema(schema)
+			if !options.Versions.Contains(message.Version) {
+				if group.unaccepted(options.MessageType, message, options) {
+					group.fallback(message)
+				}
 
-			writer := NewWriter(group, message)
-			options.Callback(message, writer)
+				message.Ack()
+				continue
+			}
 
-			message.Ack()
+			dispatcher.dispatch(message)
 		}
 	}()
 
 	return closing, nil
 }
+
+// InFlight returns the amount of messages that are currently being handled by the handlers of the group
+func (group *Group) InFlight() int64 {
+	return group.inflight.Count()
+}
+
+// handle upcasts and decodes the given message according to the given handler options before the handler callback is called.
+// The message is acknowledged once the callback returns unless the message got negative acknowledged.
+func (group *Group) handle(message *Message, options *options.HandlerOptions) {
+	group.inflight.Add()
+	defer group.inflight.Done()
+
+	if options.InFlight != nil {
+		options.InFlight.Add()
+		defer options.InFlight.Done()
+	}
+
+	// NOTE: upcasted messages are copies of the consumed message, the consumed message
+	// is negative acknowledged if the handler negative acknowledged the copy.
+	handled, err := group.Upcasters.Upcast(message, options.Version)
+	if err != nil {
+		handled = message.Copy()
+		handled.NewSchemaError(err)
+	} else {
+		group.Decode(handled, options.Schema)
+	}
+
+	writer := NewWriter(group, handled)
+	options.Callback(handled, writer)
+
+	if handled != message {
+		select {
+		case <-handled.Nacked():
+			message.Nack()
+			return
+		default:
+		}
+	}
+
+	message.Ack()
+}
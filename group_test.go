@@ -2,11 +2,15 @@ package commander
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jeroenrinzema/commander/codecs/cbor"
+	"github.com/jeroenrinzema/commander/codecs/msgpack"
 	"github.com/jeroenrinzema/commander/dialects/mock"
 	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/options"
 	"github.com/jeroenrinzema/commander/internal/types"
 )
 
@@ -30,6 +34,20 @@ func NewMockClient() (*Group, *Client) {
 	return group, client
 }
 
+// NewTestGroup constructs a new group with the given definitions and a command and event topic on a synchronous
+// mock dialect. A recorder of all produced messages is returned.
+func NewTestGroup(definitions ...options.GroupOption) (*Group, *mock.Recorder) {
+	recorder := mock.NewRecorder()
+	dialect := mock.NewDialect(mock.WithRecorder(recorder), mock.WithSynchronousDelivery())
+
+	definitions = append(definitions,
+		NewTopic("commands", dialect, CommandMessage, DefaultMode),
+		NewTopic("events", dialect, EventMessage, DefaultMode),
+	)
+
+	return NewGroup(definitions...), recorder
+}
+
 // TestProduceCommand tests if able to produce a command
 func TestProduceCommand(t *testing.T) {
 	group, client := NewMockClient()
@@ -73,6 +91,125 @@ func TestProduceEventNoTopics(t *testing.T) {
 	}
 }
 
+// TestProduceCommandRetry tests if a failed command production is retried
+func TestProduceCommandRetry(t *testing.T) {
+	dialect := mock.NewDialect(mock.WithFaults(mock.Faults{
+		PublishErrorOn: []int{1, 2},
+	}))
+
+	group := NewGroup(
+		NewTopic("commands", dialect, CommandMessage, DefaultMode),
+	)
+
+	client, _ := NewClient(group)
+	defer client.Close()
+
+	message := types.NewMessage("testing", 1, nil, nil)
+
+	err := group.ProduceCommand(message)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// NewFanOutGroup constructs a new group with a event produce topic on a healthy and on a failing mock dialect.
+// The recorders of both dialects are returned.
+func NewFanOutGroup(definitions ...options.GroupOption) (*Group, *mock.Recorder, *mock.Recorder) {
+	healthy := mock.NewRecorder()
+	failing := mock.NewRecorder()
+
+	definitions = append(definitions,
+		NewTopic("events", mock.NewDialect(mock.WithRecorder(healthy)), EventMessage, DefaultMode),
+		NewTopic("migration", mock.NewDialect(mock.WithRecorder(failing), mock.WithFaults(mock.Faults{PublishErrorRate: 1})), EventMessage, DefaultMode),
+	)
+
+	group := NewGroup(definitions...)
+	group.Retries = 0
+
+	return group, healthy, failing
+}
+
+// TestProduceEventFanOut tests if a event is produced to all event produce topics
+func TestProduceEventFanOut(t *testing.T) {
+	events := mock.NewRecorder()
+	migration := mock.NewRecorder()
+
+	group := NewGroup(
+		NewTopic("events", mock.NewDialect(mock.WithRecorder(events)), EventMessage, DefaultMode),
+		NewTopic("migration", mock.NewDialect(mock.WithRecorder(migration)), EventMessage, DefaultMode),
+	)
+
+	message := types.NewMessage("tested", 1, nil, nil)
+
+	err := group.ProduceEvent(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events.Messages()) != 1 || len(migration.Messages()) != 1 {
+		t.Fatal("event not produced to all topics")
+	}
+
+	if events.Messages()[0] != message || migration.Messages()[0] == message {
+		t.Fatal("expected the message to be produced to the primary topic and a copy to the other topics")
+	}
+
+	if migration.Messages()[0].ID != message.ID || migration.Messages()[0].Topic.Name() != "migration" {
+		t.Fatal("unexpected message copy")
+	}
+}
+
+// TestProduceEventAllPolicy tests if a aggregated error is returned when producing to one of the topics failed
+func TestProduceEventAllPolicy(t *testing.T) {
+	group, healthy, _ := NewFanOutGroup()
+
+	err := group.ProduceEvent(types.NewMessage("tested", 1, nil, nil))
+	if err == nil {
+		t.Fatal("expected a error to be returned")
+	}
+
+	failed, ok := err.(*ProduceError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(failed.Topics) != 1 || failed.Topics[0].Name() != "migration" || failed.Errors[0] != mock.ErrInjectedFault {
+		t.Fatalf("unexpected failed topics: %v", failed)
+	}
+
+	if len(healthy.Messages()) != 1 {
+		t.Fatal("event not produced to the healthy topic")
+	}
+}
+
+// TestProduceEventBestEffortPolicy tests if no error is returned when producing to at least one topic succeeded
+func TestProduceEventBestEffortPolicy(t *testing.T) {
+	group, healthy, _ := NewFanOutGroup(WithProducePolicy(ProduceBestEffort))
+
+	err := group.ProduceEvent(types.NewMessage("tested", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(healthy.Messages()) != 1 {
+		t.Fatal("event not produced to the healthy topic")
+	}
+}
+
+// TestProduceEventPrimaryPolicy tests if a event is only produced to the first produce topic
+func TestProduceEventPrimaryPolicy(t *testing.T) {
+	group, healthy, failing := NewFanOutGroup(WithProducePolicy(ProducePrimary))
+
+	err := group.ProduceEvent(types.NewMessage("tested", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(healthy.Messages()) != 1 || len(failing.Messages()) != 0 {
+		t.Fatal("event not only produced to the primary topic")
+	}
+}
+
 // TestAsyncCommand tests if plausible to create a async command
 func TestAsyncCommand(t *testing.T) {
 	group, client := NewMockClient()
@@ -456,6 +593,40 @@ func TestCommandTimestampPassed(t *testing.T) {
 	}
 }
 
+// TestConsumerGroupReplicas tests if commands are load balanced between replicas of a consumer group
+func TestConsumerGroupReplicas(t *testing.T) {
+	dialect := mock.NewDialect()
+	consumed := int32(0)
+
+	replicas := []*Group{}
+	for i := 0; i < 2; i++ {
+		replica := NewGroup(
+			NewTopic("commands", dialect.ConsumerGroup("service"), CommandMessage, DefaultMode),
+		)
+
+		replica.HandleFunc(CommandMessage, "command", func(message *Message, writer Writer) {
+			atomic.AddInt32(&consumed, 1)
+		})
+
+		replicas = append(replicas, replica)
+	}
+
+	client, _ := NewClient(replicas...)
+	defer client.Close()
+
+	amount := 10
+	for i := 0; i < amount; i++ {
+		command := types.NewMessage("command", 1, nil, nil)
+		replicas[0].ProduceCommand(command)
+	}
+
+	dialect.Flush()
+
+	if atomic.LoadInt32(&consumed) != int32(amount) {
+		t.Errorf("unexpected amount of consumed commands %d, expected %d", consumed, amount)
+	}
+}
+
 // TestMessageMarked tests if the command timestamp is passed to the produced event
 func TestMessageMarked(t *testing.T) {
 	message := types.NewMessage("testing", 1, nil, nil)
@@ -484,3 +655,200 @@ func TestNewConsumer(t *testing.T) {
 		t.Error("unexpected error", err)
 	}
 }
+
+// TestNewConsumerMultipleDialects tests if topics of different dialects are subscribed to through their own dialect
+// and if the messages of all subscriptions are merged into a single consumer.
+func TestNewConsumerMultipleDialects(t *testing.T) {
+	kafka := mock.NewDialect()
+	nats := mock.NewDialect()
+
+	group := NewGroup(
+		NewTopic("kafka", kafka, CommandMessage, DefaultMode),
+		NewTopic("nats", nats, CommandMessage, DefaultMode),
+	)
+
+	messages, closing, err := group.NewConsumer(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, topic := range group.Topics {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+
+		err := topic.Dialect().Producer().Publish(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	consumed := map[string]types.Dialect{}
+	for i := 0; i < len(group.Topics); i++ {
+		select {
+		case message := <-messages:
+			consumed[message.Topic.Name()] = message.Topic.Dialect()
+			message.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("message not consumed")
+		}
+	}
+
+	if consumed["kafka"] != kafka || consumed["nats"] != nats {
+		t.Fatalf("unexpected originating topics: %+v", consumed)
+	}
+
+	closing()
+
+	// Unsubscribing happens in the background
+	time.Sleep(50 * time.Millisecond)
+
+	for _, topic := range group.Topics {
+		message := types.NewMessage("command", 1, nil, nil)
+		message.Topic = topic
+		topic.Dialect().Producer().Publish(message)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if kafka.WaitIdle(ctx) != nil || nats.WaitIdle(ctx) != nil {
+		t.Fatal("messages delivered to a closed consumer")
+	}
+}
+
+// TestHandleSchemaError tests if schema decoding errors are reported to the handler
+func TestHandleSchemaError(t *testing.T) {
+	dialect := mock.NewDialect(mock.WithSynchronousDelivery())
+	group := NewGroup(
+		WithJSONCodec(),
+		NewTopic("events", dialect, EventMessage, DefaultMode),
+	)
+
+	errs := make(chan error, 2)
+	group.HandleFunc(EventMessage, "created", func(message *Message, writer Writer) {
+		errs <- message.SchemaError()
+	})
+
+	group.ProduceEvent(types.NewMessage("created", 1, nil, []byte(`{"valid":true}`)))
+	group.ProduceEvent(types.NewMessage("created", 1, nil, []byte(`{invalid`)))
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errs; err == nil {
+		t.Fatal("expected a schema error for invalid data")
+	}
+}
+
+// TestCodecNegotiation tests if consumed messages are decoded with the codec of their content type
+// and if responses are encoded with the codec of the consumed message.
+func TestCodecNegotiation(t *testing.T) {
+	recorder := mock.NewRecorder()
+	dialect := mock.NewDialect(mock.WithRecorder(recorder), mock.WithSynchronousDelivery())
+	group := NewGroup(
+		WithJSONCodec(),
+		WithCodecs(msgpack.NewCodec(), cbor.NewCodec()),
+		NewTopic("commands", dialect, CommandMessage, DefaultMode),
+		NewTopic("events", dialect, EventMessage, DefaultMode),
+	)
+
+	names := make(chan interface{}, 3)
+	group.HandleFunc(CommandMessage, "create", func(message *Message, writer Writer) {
+		if message.SchemaError() != nil {
+			t.Error(message.SchemaError())
+			return
+		}
+
+		names <- message.Schema().(map[string]interface{})["name"]
+		writer.EventValue("created", 1, nil, message.Schema())
+	})
+
+	for _, codec := range []options.Codec{&options.JSONCodec{}, msgpack.NewCodec(), cbor.NewCodec()} {
+		data, err := codec.Marshal(map[string]interface{}{"name": "john"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		command := types.NewMessage("create", 1, nil, data)
+		command.NewCtx(metadata.NewContentTypeContext(command.Ctx(), codec.(options.ContentTypeCodec).ContentType()))
+
+		err = group.ProduceCommand(command)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name := <-names; name != "john" {
+			t.Fatalf("unexpected decoded name: %v", name)
+		}
+
+		events := recorder.Find(mock.WithParent(command.ID))
+		if len(events) != 1 {
+			t.Fatal("response event not produced")
+		}
+
+		expected, _ := metadata.ContentTypeFromContext(command.Ctx())
+		contentType, _ := metadata.ContentTypeFromContext(events[0].Ctx())
+		if contentType != expected {
+			t.Fatalf("unexpected response content type %s, expected %s", contentType, expected)
+		}
+	}
+}
+
+// TestCodecNegotiationDefault tests if messages without a known content type are decoded with the default codec
+func TestCodecNegotiationDefault(t *testing.T) {
+	group := NewGroup(
+		WithJSONCodec(),
+		WithCodecs(msgpack.NewCodec()),
+	)
+
+	message := types.NewMessage("create", 1, nil, []byte(`{"name":"john"}`))
+	if group.CodecFor(message) != group.Codec {
+		t.Fatal("default codec not used for a message without content type")
+	}
+
+	message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), "application/xml"))
+	if group.CodecFor(message) != group.Codec {
+		t.Fatal("default codec not used for a message with a unknown content type")
+	}
+
+	group.Decode(message, nil)
+	if message.SchemaError() != nil || message.Schema().(map[string]interface{})["name"] != "john" {
+		t.Fatal("message not decoded with the default codec")
+	}
+}
+
+// TestProduceContentType tests if the content type header of produced messages is only set when the payload is encoded by the group
+func TestProduceContentType(t *testing.T) {
+	dialect := mock.NewDialect()
+	group := NewGroup(
+		WithJSONCodec(),
+		NewTopic("events", dialect, EventMessage, ProduceMode),
+	)
+
+	raw := types.NewMessage("created", 1, nil, []byte("raw"))
+	err := group.ProduceEvent(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, has := metadata.ContentTypeFromContext(raw.Ctx()); has {
+		t.Fatal("content type set on a message not encoded by the group")
+	}
+
+	encoded := types.NewMessage("created", 1, nil, nil)
+	err = group.Encode(encoded, map[string]interface{}{"name": "john"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = group.ProduceEvent(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contentType, _ := metadata.ContentTypeFromContext(encoded.Ctx())
+	if contentType != "application/json" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+}
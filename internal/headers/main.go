@@ -0,0 +1,106 @@
+package headers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Commander message headers
+const (
+	HeaderID              = "x-id"
+	HeaderKey             = "x-key"
+	HeaderAction          = "x-action"
+	HeaderStatusCode      = "x-status-code"
+	HeaderVersion         = "x-version"
+	HeaderEOS             = "x-eos"
+	HeaderParentID        = "x-parent-id"
+	HeaderParentTimestamp = "x-parent-timestamp"
+)
+
+// Marshal returns the metadata of the given message as header key/values.
+// Custom headers stored inside the message context are included.
+// The message key is not included since most protocols support message keys natively.
+func Marshal(message *types.Message) map[string]string {
+	headers := map[string]string{
+		HeaderID:         message.ID,
+		HeaderAction:     message.Action,
+		HeaderVersion:    message.Version.String(),
+		HeaderEOS:        message.EOS.String(),
+		HeaderStatusCode: message.Status.String(),
+	}
+
+	parent, has := metadata.ParentIDFromContext(message.Ctx())
+	if has {
+		headers[HeaderParentID] = string(parent)
+	}
+
+	timestamp, has := metadata.ParentTimestampFromContext(message.Ctx())
+	if has {
+		headers[HeaderParentTimestamp] = strconv.FormatInt(time.Time(timestamp).UnixNano(), 10)
+	}
+
+	kv, has := metadata.HeaderFromContext(message.Ctx())
+	if has {
+		for key, value := range kv {
+			headers[key] = value.String()
+		}
+	}
+
+	return headers
+}
+
+// Unmarshal applies the given header key/values to the given message.
+// Headers that are not commander headers are stored as custom headers inside the message context.
+// Headers containing malformed values are ignored.
+func Unmarshal(message *types.Message, headers map[string]string) {
+	ctx := message.Ctx()
+	custom := metadata.Header{}
+
+	for key, value := range headers {
+		switch key {
+		case HeaderID:
+			message.ID = value
+		case HeaderKey:
+			message.Key = []byte(value)
+		case HeaderAction:
+			message.Action = value
+		case HeaderStatusCode:
+			status, err := strconv.ParseInt(value, 10, 16)
+			if err != nil {
+				continue
+			}
+
+			message.Status = types.StatusCode(status)
+		case HeaderVersion:
+			version, err := strconv.ParseInt(value, 10, 8)
+			if err != nil {
+				continue
+			}
+
+			message.Version = types.Version(version)
+		case HeaderEOS:
+			message.EOS = message.EOS.Parse(value)
+		case HeaderParentID:
+			ctx = metadata.NewParentIDContext(ctx, metadata.ParentID(value))
+		case HeaderParentTimestamp:
+			unix, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			ctx = metadata.NewParentTimestampContext(ctx, metadata.ParentTimestamp(time.Unix(0, unix)))
+		default:
+			custom[key] = strings.Split(value, metadata.HeaderValueDevider)
+		}
+	}
+
+	if len(custom) > 0 {
+		ctx = metadata.AppendToHeaderContext(ctx, custom)
+	}
+
+	message.NewCtx(ctx)
+}
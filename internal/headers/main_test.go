@@ -0,0 +1,50 @@
+package headers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/metadata"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestMarshalUnmarshal tests if the message metadata is preserved when marshalled and unmarshalled
+func TestMarshalUnmarshal(t *testing.T) {
+	parent := types.NewMessage("parent", 1, nil, nil)
+	message := parent.NewMessage("child", 2, nil, nil)
+	message.EOS = true
+	message.Status = types.StatusConflict
+	message.NewCtx(metadata.AppendToHeaderContext(message.Ctx(), metadata.Header{
+		"custom": metadata.HeaderValue{"a", "b"},
+	}))
+
+	kv := Marshal(message)
+
+	result := &types.Message{}
+	result.NewCtx(context.Background())
+	Unmarshal(result, kv)
+
+	if result.ID != message.ID || result.Action != message.Action || result.Version != message.Version {
+		t.Fatal("message id, action or version not preserved")
+	}
+
+	if result.EOS != message.EOS || result.Status != message.Status {
+		t.Fatal("message eos or status not preserved")
+	}
+
+	id, has := metadata.ParentIDFromContext(result.Ctx())
+	if !has || string(id) != parent.ID {
+		t.Fatal("parent id not preserved")
+	}
+
+	timestamp, has := metadata.ParentTimestampFromContext(result.Ctx())
+	if !has || !time.Time(timestamp).Equal(parent.Timestamp.Round(0)) {
+		t.Fatal("parent timestamp not preserved")
+	}
+
+	custom, has := metadata.HeaderFromContext(result.Ctx())
+	if !has || custom["custom"].String() != "a;b" {
+		t.Fatal("custom headers not preserved")
+	}
+}
@@ -71,3 +71,32 @@ func ParentIDFromContext(ctx context.Context) (id ParentID, ok bool) {
 	id, ok = ctx.Value(CtxParentID).(ParentID)
 	return
 }
+
+// NewContentTypeContext returns a new context with the given content type set as content type header.
+// Other headers stored inside the given context are preserved.
+func NewContentTypeContext(ctx context.Context, contentType string) context.Context {
+	header := Header{}
+	if existing, has := HeaderFromContext(ctx); has {
+		for key, value := range existing {
+			header[key] = value
+		}
+	}
+
+	header[ContentTypeHeader] = HeaderValue{contentType}
+	return NewHeaderContext(ctx, header)
+}
+
+// ContentTypeFromContext returns the content type header in ctx if it exists.
+func ContentTypeFromContext(ctx context.Context) (contentType string, ok bool) {
+	header, has := HeaderFromContext(ctx)
+	if !has {
+		return "", false
+	}
+
+	value, has := header[ContentTypeHeader]
+	if !has || len(value) == 0 {
+		return "", false
+	}
+
+	return value.String(), true
+}
@@ -8,6 +8,8 @@ import (
 const (
 	// HeaderValueDevider represents the UTF-8 value that is used to devide values
 	HeaderValueDevider = ";"
+	// ContentTypeHeader represents the header key containing the content type of the message data
+	ContentTypeHeader = "content-type"
 )
 
 // HeaderValue a slice of header values
@@ -2,6 +2,8 @@ package options
 
 import (
 	"encoding/json"
+
+	"github.com/jeroenrinzema/commander/internal/types"
 )
 
 // Codec Codec defines the interface commander uses to encode and decode messages.
@@ -19,12 +21,46 @@ type Codec interface {
 	Schema() interface{}
 }
 
+// ContentTypeCodec is implemented by codecs that define the content type of the encoded messages.
+// The content type is set as header on all messages produced by a group using the codec.
+type ContentTypeCodec interface {
+	ContentType() string
+}
+
+// MessageCodec is implemented by codecs that decode messages into a schema based on the message action and version.
+// When implemented is UnmarshalMessage used instead of Unmarshal to decode consumed messages.
+type MessageCodec interface {
+	UnmarshalMessage(message *types.Message) (interface{}, error)
+}
+
+// MessageMarshaler is implemented by codecs that encode a schema based on the message action and version.
+// MarshalMessage encodes the given schema and stores the result as the message data.
+type MessageMarshaler interface {
+	MarshalMessage(message *types.Message, s interface{}) error
+}
+
+// Codecs represents a set of codecs registered by the content type of their encoded messages
+type Codecs map[string]Codec
+
+// Register registers the given codecs by their content type.
+// Codecs that do not implement ContentTypeCodec are ignored.
+func (codecs Codecs) Register(registered ...Codec) {
+	for _, codec := range registered {
+		typed, ok := codec.(ContentTypeCodec)
+		if !ok {
+			continue
+		}
+
+		codecs[typed.ContentType()] = codec
+	}
+}
+
 // DefaultCodec returns the default codec that preforms no action during marshalling ur unmarshalling
 func DefaultCodec() Codec {
 	return &IgnoreCodec{}
 }
 
-// IgnoreCodec is the default codec interperter that preforms no action during marshalling ur unmarshalling
+// IgnoreCodec is the default codec that preforms no action during marshalling ur unmarshalling
 type IgnoreCodec struct {
 }
 
@@ -48,12 +84,12 @@ func (codec *IgnoreCodec) Unmarshal(data []byte, s interface{}) (err error) {
 	return nil
 }
 
-// WithJSONCodec constructs a new JSON message interperter used for encoding and decoding messages.
+// WithJSONCodec constructs a new JSON message codec used for encoding and decoding messages.
 func WithJSONCodec() GroupOption {
 	return &JSONCodec{}
 }
 
-// JSONCodec is a JSON message codec interperter used for encoding and decoding messages.
+// JSONCodec is a JSON message codec used for encoding and decoding messages.
 // All messages are decoded as map[string]interface{}.
 type JSONCodec struct {
 }
@@ -68,6 +104,11 @@ func (codec *JSONCodec) Apply(options *GroupOptions) {
 	options.Codec = codec
 }
 
+// ContentType returns the content type of JSON encoded messages
+func (codec *JSONCodec) ContentType() string {
+	return "application/json"
+}
+
 // Marshal returns the wire format of s.
 func (codec *JSONCodec) Marshal(s interface{}) (bb []byte, err error) {
 	bb, err = json.Marshal(s)
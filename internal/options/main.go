@@ -13,6 +13,19 @@ const (
 	DefaultTimeout = 5 * time.Second
 )
 
+// ProducePolicy represents the policy used when producing a message to multiple topics
+type ProducePolicy int8
+
+// Available produce policies
+const (
+	// ProduceAll produces the message to all produce topics and returns a error if producing to one of the topics failed
+	ProduceAll ProducePolicy = iota
+	// ProduceBestEffort produces the message to all produce topics and only returns a error if producing to all topics failed
+	ProduceBestEffort
+	// ProducePrimary produces the message only to the first produce topic
+	ProducePrimary
+)
+
 // NewServerOptions applies the given serve options to construct a new server options definition
 func NewServerOptions(options []ServerOption) (result *ServerOptions) {
 	result = &ServerOptions{}
@@ -36,9 +49,11 @@ type ServerOptions struct {
 // NewGroupOptions applies the given serve options to construct a new group options definition
 func NewGroupOptions(options []GroupOption) (result *GroupOptions) {
 	result = &GroupOptions{
-		Timeout: DefaultTimeout,
-		Retries: DefaultRetries,
-		Codec:   DefaultCodec(),
+		Timeout:   DefaultTimeout,
+		Retries:   DefaultRetries,
+		Codec:     DefaultCodec(),
+		Codecs:    Codecs{},
+		Upcasters: types.NewUpcasters(),
 	}
 
 	for _, option := range options {
@@ -55,10 +70,15 @@ type GroupOption interface {
 
 // GroupOptions represent the available set of group options
 type GroupOptions struct {
-	Timeout time.Duration
-	Codec   Codec
-	Retries int8
-	Topics  []types.Topic
+	Timeout   time.Duration
+	Codec     Codec
+	Codecs    Codecs
+	Retries   int8
+	Topics    []types.Topic
+	Policy    ProducePolicy
+	Upcasters *types.Upcasters
+	// VersionFallback is called with consumed messages whose version is not accepted by any handler
+	VersionFallback types.HandlerFunc
 }
 
 // NewHandlerOptions applies the given serve options to construct a new handle options definition
@@ -79,7 +99,36 @@ type HandlerOption interface {
 // HandlerOptions represent the available set of handle options
 type HandlerOptions struct {
 	Action      string
+	Version     types.Version
+	Versions    types.VersionRange
 	MessageType types.MessageType
 	Schema      func() interface{}
 	Callback    types.HandlerFunc
+	Concurrency int
+	KeyOrdering bool
+	InFlight    *types.InFlight
+}
+
+// NewBridgeOptions applies the given bridge options to construct a new bridge options definition
+func NewBridgeOptions(options []BridgeOption) (result *BridgeOptions) {
+	result = &BridgeOptions{
+		Actions: map[string]string{},
+	}
+
+	for _, option := range options {
+		option.Apply(result)
+	}
+
+	return result
+}
+
+// BridgeOption sets options such as message filters and action renames
+type BridgeOption interface {
+	Apply(*BridgeOptions)
+}
+
+// BridgeOptions represent the available set of bridge options
+type BridgeOptions struct {
+	Filter  func(*types.Message) bool
+	Actions map[string]string
 }
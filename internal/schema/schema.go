@@ -0,0 +1,26 @@
+package schema
+
+import "reflect"
+
+// Unmarshal calls the given decode method with the value the wire format should be decoded into.
+// If s is a pointer to a interface containing a pointer is the wire format decoded into the contained pointer,
+// otherwise is the decoded value assigned to the interface. This matches the behaviour of encoding/json.
+func Unmarshal(s interface{}, decode func(v interface{}) error) error {
+	ptr, ok := s.(*interface{})
+	if !ok {
+		return decode(s)
+	}
+
+	if value := reflect.ValueOf(*ptr); value.Kind() == reflect.Ptr && !value.IsNil() {
+		return decode(*ptr)
+	}
+
+	var value interface{}
+	err := decode(&value)
+	if err != nil {
+		return err
+	}
+
+	*ptr = value
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package testutil contains helpers shared by the commander and dialect tests
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Timeout represents the duration a message is awaited before the test fails
+var Timeout = time.Second
+
+// Await awaits the next message of the given subscription.
+// The test fails if no message is received before the timeout is reached.
+func Await(t testing.TB, messages <-chan *types.Message) *types.Message {
+	t.Helper()
+
+	select {
+	case message := <-messages:
+		return message
+	case <-time.After(Timeout):
+		t.Fatal("timeout reached")
+	}
+
+	return nil
+}
+
+// Eventually awaits till the given condition is met.
+// The test fails if the condition is not met before the given timeout is reached.
+func Eventually(t testing.TB, timeout time.Duration, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the deadline")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Open opens the given dialect for the given topics. The dialect is closed once the test is completed.
+func Open(t testing.TB, dialect types.Dialect, topics ...types.Topic) {
+	t.Helper()
+
+	err := dialect.Open(topics)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		dialect.Close()
+	})
+}
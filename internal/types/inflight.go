@@ -0,0 +1,24 @@
+package types
+
+import "sync/atomic"
+
+// InFlight keeps track of the amount of messages that are currently being handled.
+// The zero value is ready to use and could be shared between multiple handlers.
+type InFlight struct {
+	count int64
+}
+
+// Add marks a new message as in-flight
+func (inflight *InFlight) Add() {
+	atomic.AddInt64(&inflight.count, 1)
+}
+
+// Done marks a in-flight message as handled
+func (inflight *InFlight) Done() {
+	atomic.AddInt64(&inflight.count, -1)
+}
+
+// Count returns the amount of messages that are currently in-flight
+func (inflight *InFlight) Count() int64 {
+	return atomic.LoadInt64(&inflight.count)
+}
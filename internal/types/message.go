@@ -127,12 +127,13 @@ type Message struct {
 	EOS       EOS        `json:"eos"`
 	Timestamp time.Time  `json:"timestamp"`
 
-	ctx      context.Context
-	schema   interface{}
-	ack      chan struct{}
-	nack     chan struct{}
-	response Resolved
-	mutex    sync.RWMutex
+	ctx       context.Context
+	schema    interface{}
+	schemaErr error
+	ack       chan struct{}
+	nack      chan struct{}
+	response  Resolved
+	mutex     sync.RWMutex
 }
 
 // Schema returns the decoded message schema
@@ -145,6 +146,17 @@ func (message *Message) NewSchema(v interface{}) {
 	message.schema = v
 }
 
+// SchemaError returns the error that occurred while decoding the message schema.
+// Nil is returned if the message schema got decoded successfully.
+func (message *Message) SchemaError() error {
+	return message.schemaErr
+}
+
+// NewSchemaError overrides the message schema decoding error with the given error
+func (message *Message) NewSchemaError(err error) {
+	message.schemaErr = err
+}
+
 // NewError construct a new error message with the given message as parent
 func (message *Message) NewError(action string, status StatusCode, err error) *Message {
 	child := message.NewMessage(action, message.Version, message.Key, []byte(err.Error()))
@@ -170,6 +182,31 @@ func (message *Message) NewMessage(action string, version Version, key metadata.
 	return child
 }
 
+// Copy constructs a unresolved copy of the given message.
+// The copy shares the message id, metadata context, schema and the key and data slices with the original message.
+func (message *Message) Copy() *Message {
+	message.mutex.RLock()
+	defer message.mutex.RUnlock()
+
+	return &Message{
+		ID:        message.ID,
+		Status:    message.Status,
+		Topic:     message.Topic,
+		Action:    message.Action,
+		Version:   message.Version,
+		Data:      message.Data,
+		Key:       message.Key,
+		EOS:       message.EOS,
+		Timestamp: message.Timestamp,
+		ctx:       message.ctx,
+		schema:    message.schema,
+		schemaErr: message.schemaErr,
+		ack:       make(chan struct{}, 0),
+		nack:      make(chan struct{}, 0),
+		response:  UnkownResolvedStatus,
+	}
+}
+
 // Reset set's up a new async resolver that awaits untill resolved
 func (message *Message) Reset() {
 	if message == nil {
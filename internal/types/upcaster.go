@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Upcaster transforms the given message from its current version into the next version.
+// The message data should be replaced instead of modified in place since the data could be shared with other messages.
+// The message version is incremented once the upcaster returns.
+type Upcaster func(message *Message) error
+
+// UpcasterNotFoundError is returned when no upcaster is registered for the given action and version
+type UpcasterNotFoundError struct {
+	Action  string
+	Version Version
+}
+
+// Error returns the action and version for which no upcaster is registered
+func (err *UpcasterNotFoundError) Error() string {
+	return fmt.Sprintf("no upcaster registered for action %s version %s", err.Action, err.Version)
+}
+
+type upcast struct {
+	action  string
+	version Version
+}
+
+// NewUpcasters constructs a new empty upcaster registry
+func NewUpcasters() *Upcasters {
+	return &Upcasters{
+		registry: make(map[upcast]Upcaster),
+	}
+}
+
+// Upcasters represents a registry of upcasters by action and version
+type Upcasters struct {
+	registry map[upcast]Upcaster
+	mutex    sync.RWMutex
+}
+
+// Register registers the given upcaster to transform messages with the given action from the given version into the next version
+func (upcasters *Upcasters) Register(action string, version int8, upcaster Upcaster) {
+	upcasters.mutex.Lock()
+	defer upcasters.mutex.Unlock()
+
+	upcasters.registry[upcast{action, Version(version)}] = upcaster
+}
+
+// Upcast transforms the given message step by step into the given target version.
+// The given message is returned if its version is equal to or newer than the target version,
+// otherwise is a upcasted copy of the message returned. A UpcasterNotFoundError is returned
+// if no upcaster is registered for one of the steps.
+func (upcasters *Upcasters) Upcast(message *Message, target Version) (*Message, error) {
+	if message.Version >= target {
+		return message, nil
+	}
+
+	upcasted := message.Copy()
+
+	for upcasted.Version < target {
+		upcasters.mutex.RLock()
+		upcaster, has := upcasters.registry[upcast{upcasted.Action, upcasted.Version}]
+		upcasters.mutex.RUnlock()
+
+		if !has {
+			return nil, &UpcasterNotFoundError{upcasted.Action, upcasted.Version}
+		}
+
+		err := upcaster(upcasted)
+		if err != nil {
+			return nil, err
+		}
+
+		upcasted.Version++
+	}
+
+	return upcasted, nil
+}
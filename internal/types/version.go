@@ -0,0 +1,136 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionRange represents a inclusive range of message versions.
+// A null version as minimum or maximum represents a unbounded side of the range.
+// The zero value contains all versions.
+type VersionRange struct {
+	Min Version
+	Max Version
+}
+
+// Contains checks if the given version is inside the version range
+func (r VersionRange) Contains(version Version) bool {
+	if r.Min != NullVersion && version < r.Min {
+		return false
+	}
+
+	if r.Max != NullVersion && version > r.Max {
+		return false
+	}
+
+	return true
+}
+
+// String returns the version range as a expression
+func (r VersionRange) String() string {
+	switch {
+	case r.Min == NullVersion && r.Max == NullVersion:
+		return "*"
+	case r.Min == r.Max:
+		return r.Min.String()
+	case r.Max == NullVersion:
+		return ">=" + r.Min.String()
+	case r.Min == NullVersion:
+		return "<=" + r.Max.String()
+	default:
+		return r.Min.String() + "-" + r.Max.String()
+	}
+}
+
+// ParseVersionRange parses the given version range expression.
+// Supported expressions are "*", "2", "=2", ">=2", ">2", "<=2", "<2" and "1-3".
+// Multiple expressions could be combined with a comma (ex: ">=2,<4"), the range then contains the versions matching all expressions.
+func ParseVersionRange(expr string) (VersionRange, error) {
+	result := VersionRange{}
+
+	for _, part := range strings.Split(expr, ",") {
+		r, err := parseVersionExpression(strings.TrimSpace(part))
+		if err != nil {
+			return VersionRange{}, err
+		}
+
+		if r.Min != NullVersion && (result.Min == NullVersion || r.Min > result.Min) {
+			result.Min = r.Min
+		}
+
+		if r.Max != NullVersion && (result.Max == NullVersion || r.Max < result.Max) {
+			result.Max = r.Max
+		}
+	}
+
+	return result, nil
+}
+
+// MustParseVersionRange parses the given version range expression and panics if the expression is invalid
+func MustParseVersionRange(expr string) VersionRange {
+	r, err := ParseVersionRange(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+func parseVersionExpression(expr string) (VersionRange, error) {
+	invalid := fmt.Errorf("invalid version range expression %q", expr)
+
+	parse := func(value string) (Version, error) {
+		version, err := strconv.ParseInt(value, 10, 8)
+		if err != nil || version <= int64(NullVersion) {
+			return NullVersion, invalid
+		}
+
+		return Version(version), nil
+	}
+
+	switch {
+	case expr == "" || expr == "*":
+		return VersionRange{}, nil
+	case strings.HasPrefix(expr, ">="):
+		version, err := parse(expr[2:])
+		return VersionRange{Min: version}, err
+	case strings.HasPrefix(expr, "<="):
+		version, err := parse(expr[2:])
+		return VersionRange{Max: version}, err
+	case strings.HasPrefix(expr, ">"):
+		version, err := parse(expr[1:])
+		if err != nil || version == 127 {
+			return VersionRange{}, invalid
+		}
+
+		return VersionRange{Min: version + 1}, nil
+	case strings.HasPrefix(expr, "<"):
+		version, err := parse(expr[1:])
+		if err != nil || version == 1 {
+			return VersionRange{}, invalid
+		}
+
+		return VersionRange{Max: version - 1}, nil
+	case strings.HasPrefix(expr, "="):
+		version, err := parse(expr[1:])
+		return VersionRange{Min: version, Max: version}, err
+	case strings.Contains(expr, "-"):
+		bounds := strings.SplitN(expr, "-", 2)
+
+		min, err := parse(bounds[0])
+		if err != nil {
+			return VersionRange{}, err
+		}
+
+		max, err := parse(bounds[1])
+		if err != nil || max < min {
+			return VersionRange{}, invalid
+		}
+
+		return VersionRange{Min: min, Max: max}, nil
+	default:
+		version, err := parse(expr)
+		return VersionRange{Min: version, Max: version}, err
+	}
+}
@@ -34,4 +34,20 @@ type Writer interface {
 
 	// CommandEOS alias of Command
 	CommandEOS(action string, version int8, key []byte, data []byte) (*Message, error)
+
+	// EventValue encodes the given value with the group codec and produces it as a new event to the assigned group.
+	// The produced event is marked as EOS (end of stream). No event is produced if the value could not be encoded.
+	EventValue(action string, version int8, key []byte, value interface{}) (*Message, error)
+
+	// EventStreamValue encodes the given value with the group codec and produces it as a new event to the assigned group.
+	// The produced event is one of many events in the event stream. No event is produced if the value could not be encoded.
+	EventStreamValue(action string, version int8, key []byte, value interface{}) (*Message, error)
+
+	// CommandValue encodes the given value with the group codec and produces it as a new command to the assigned group.
+	// The produced command is marked as EOS (end of stream). No command is produced if the value could not be encoded.
+	CommandValue(action string, version int8, key []byte, value interface{}) (*Message, error)
+
+	// CommandStreamValue encodes the given value with the group codec and produces it as a new command to the assigned group.
+	// The produced command is one of many commands in the command stream. No command is produced if the value could not be encoded.
+	CommandStreamValue(action string, version int8, key []byte, value interface{}) (*Message, error)
 }
@@ -20,6 +20,67 @@ func WithAwaitTimeout(d time.Duration) options.GroupOption {
 	return &timeout{d}
 }
 
+type producePolicy struct {
+	policy options.ProducePolicy
+}
+
+func (p *producePolicy) Apply(options *options.GroupOptions) {
+	options.Policy = p.policy
+}
+
+// WithProducePolicy returns a GroupOption that configures the policy used when producing to multiple topics
+func WithProducePolicy(p options.ProducePolicy) options.GroupOption {
+	return &producePolicy{p}
+}
+
+type codecs struct {
+	codecs []options.Codec
+}
+
+func (c *codecs) Apply(options *options.GroupOptions) {
+	options.Codecs.Register(c.codecs...)
+}
+
+// WithCodecs returns a GroupOption that registers the given codecs by their content type.
+// Consumed messages are decoded with the codec registered for their content type header,
+// messages without a content type or with a unknown content type are decoded with the group default codec.
+// Codecs that do not define a content type are ignored.
+func WithCodecs(c ...options.Codec) options.GroupOption {
+	return &codecs{c}
+}
+
+type upcaster struct {
+	action   string
+	version  int8
+	upcaster types.Upcaster
+}
+
+func (u *upcaster) Apply(options *options.GroupOptions) {
+	options.Upcasters.Register(u.action, u.version, u.upcaster)
+}
+
+// WithUpcaster returns a GroupOption that registers a upcaster transforming messages
+// with the given action from the given version into the next version.
+func WithUpcaster(action string, version int8, u types.Upcaster) options.GroupOption {
+	return &upcaster{action, version, u}
+}
+
+type versionFallback struct {
+	handler types.HandlerFunc
+}
+
+func (v *versionFallback) Apply(options *options.GroupOptions) {
+	options.VersionFallback = v.handler
+}
+
+// WithVersionFallback returns a GroupOption that configures the fallback called with consumed messages
+// whose version is not accepted by any of the handlers or router handlers registered for the message action.
+// Messages of actions without handlers are not passed to the fallback. The fallback could be a default
+// handler, VersionNotSupported to produce a error event or DeadLetter to forward the message to a dead letter topic.
+func WithVersionFallback(fallback types.HandlerFunc) options.GroupOption {
+	return &versionFallback{fallback}
+}
+
 type action struct {
 	name string
 }
@@ -33,6 +94,76 @@ func WithAction(n string) options.HandlerOption {
 	return &action{n}
 }
 
+type version struct {
+	value types.Version
+}
+
+func (v *version) Apply(options *options.HandlerOptions) {
+	options.Version = v.value
+}
+
+// WithVersion returns a HandleOptions that configures the message version the handle expects.
+// Consumed messages of older versions are upcasted to the given version before the handle is called.
+func WithVersion(v int8) options.HandlerOption {
+	return &version{types.Version(v)}
+}
+
+type versionRange struct {
+	value types.VersionRange
+}
+
+func (v *versionRange) Apply(options *options.HandlerOptions) {
+	options.Versions = v.value
+}
+
+// WithVersionRange returns a HandleOptions that configures the range of message versions accepted by the handle.
+// Consumed messages with a version outside of the given range are ignored by the handle.
+// The version range is checked before consumed messages are upcasted (see WithVersion).
+func WithVersionRange(r types.VersionRange) options.HandlerOption {
+	return &versionRange{r}
+}
+
+type concurrency struct {
+	workers int
+}
+
+func (c *concurrency) Apply(options *options.HandlerOptions) {
+	options.Concurrency = c.workers
+}
+
+// WithConcurrency returns a HandleOptions that configures the maximum amount of messages handled in parallel by the handle.
+// Messages are handled one at a time if the given amount is lower than two. Consumed messages are resolved once handled,
+// messages are only handled in parallel if the dialect delivers new messages before the previous messages are resolved.
+func WithConcurrency(n int) options.HandlerOption {
+	return &concurrency{n}
+}
+
+type keyOrdering struct{}
+
+func (k *keyOrdering) Apply(options *options.HandlerOptions) {
+	options.KeyOrdering = true
+}
+
+// WithKeyOrdering returns a HandleOptions that guarantees that messages with the same key are handled
+// in the order they are consumed when combined with WithConcurrency. Messages are assigned to a worker by their key.
+func WithKeyOrdering() options.HandlerOption {
+	return &keyOrdering{}
+}
+
+type inflight struct {
+	counter *types.InFlight
+}
+
+func (i *inflight) Apply(options *options.HandlerOptions) {
+	options.InFlight = i.counter
+}
+
+// WithInFlight returns a HandleOptions that keeps track of the messages currently being handled by the handle in the given counter.
+// The counter could be shared between multiple handles.
+func WithInFlight(counter *types.InFlight) options.HandlerOption {
+	return &inflight{counter}
+}
+
 type messageType struct {
 	value types.MessageType
 }
@@ -71,3 +202,31 @@ func (s *schema) Apply(options *options.HandlerOptions) {
 func WithMessageSchema(f func() interface{}) options.HandlerOption {
 	return &schema{f}
 }
+
+type bridgeFilter struct {
+	filter func(*types.Message) bool
+}
+
+func (f *bridgeFilter) Apply(options *options.BridgeOptions) {
+	options.Filter = f.filter
+}
+
+// WithBridgeFilter returns a BridgeOption that configures the message filter of a bridge.
+// Messages for which the filter returns false are acknowledged without being republished.
+func WithBridgeFilter(f func(*types.Message) bool) options.BridgeOption {
+	return &bridgeFilter{f}
+}
+
+type actionRename struct {
+	from string
+	to   string
+}
+
+func (r *actionRename) Apply(options *options.BridgeOptions) {
+	options.Actions[r.from] = r.to
+}
+
+// WithActionRename returns a BridgeOption that renames the action of bridged messages
+func WithActionRename(from string, to string) options.BridgeOption {
+	return &actionRename{from, to}
+}
@@ -0,0 +1,261 @@
+package commander
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// Wildcard represents the suffix of a prefix action (ex: "account.*")
+const Wildcard = "*"
+
+// NewRouter constructs a new router consuming messages of the given message type through a single consumer.
+// Consumed messages are dispatched to the handlers registered for the message action.
+// The router should be closed once it is no longer used.
+func (group *Group) NewRouter(sort types.MessageType) (*Router, error) {
+	messages, closing, err := group.NewConsumer(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	router := &Router{
+		group:       group,
+		sort:        sort,
+		routes:      make(map[string][]*options.HandlerOptions),
+		dispatchers: make(map[*options.HandlerOptions]*dispatcher),
+		closing:     closing,
+	}
+
+	router.deregister = group.register(sort, &registration{
+		owner:   router,
+		matches: router.matches,
+		accepts: router.accepts,
+	})
+
+	router.wg.Add(1)
+	go func() {
+		defer router.wg.Done()
+		for message := range messages {
+			router.dispatch(message)
+		}
+	}()
+
+	return router, nil
+}
+
+// Router dispatches the messages of a single consumer to the handlers registered for the message action.
+// Handlers are registered for a exact action or for a action prefix ending with a wildcard (ex: "account.*").
+// Exact actions take precedence over prefixes, longer prefixes take precedence over shorter prefixes.
+// Messages whose action is not matched by any handler are passed to the unhandled handler.
+type Router struct {
+	group       *Group
+	sort        types.MessageType
+	routes      map[string][]*options.HandlerOptions
+	dispatchers map[*options.HandlerOptions]*dispatcher
+	prefixes    []string
+	unhandled   types.HandlerFunc
+	closing     Close
+	deregister  func()
+	mutex       sync.RWMutex
+	wg          sync.WaitGroup
+	removing    sync.WaitGroup
+}
+
+// Handle registers the given handler for the given action or action prefix
+func (router *Router) Handle(action string, handler Handler) (Close, error) {
+	return router.HandleFunc(action, handler.Handle)
+}
+
+// HandleFunc registers the given callback for the given action or action prefix
+func (router *Router) HandleFunc(action string, callback HandlerFunc) (Close, error) {
+	return router.HandleContext(
+		WithAction(action),
+		WithCallback(callback),
+	)
+}
+
+// HandleContext registers a handler constructed from the given definitions.
+// A action is required, the message type option is ignored since the router consumes a single message type.
+// Multiple handlers could be registered for the same action with different version ranges, a message
+// is passed to the first registered handler accepting the message version. Messages whose action is matched
+// but whose version is not accepted by any handler of the router or group are passed to the group version fallback.
+// Concurrency options (ex: WithConcurrency) are applied per handler.
+func (router *Router) HandleContext(definitions ...options.HandlerOption) (Close, error) {
+	options := options.NewHandlerOptions(definitions)
+	if options.Action == "" {
+		return nil, ErrNoAction
+	}
+
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	if len(router.routes[options.Action]) == 0 && strings.HasSuffix(options.Action, Wildcard) {
+		router.prefixes = append(router.prefixes, options.Action)
+		sort.SliceStable(router.prefixes, func(i, j int) bool {
+			return len(router.prefixes[i]) > len(router.prefixes[j])
+		})
+	}
+
+	router.routes[options.Action] = append(router.routes[options.Action], options)
+	router.dispatchers[options] = newDispatcher(router.group, options)
+
+	once := sync.Once{}
+	closing := func() {
+		once.Do(func() {
+			router.remove(options)
+		})
+	}
+
+	return closing, nil
+}
+
+// Unhandled registers the given callback for messages whose action is not matched by any handler.
+// Messages whose action is not matched are acknowledged if no unhandled handler is registered.
+func (router *Router) Unhandled(callback HandlerFunc) {
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	router.unhandled = callback
+}
+
+// Close closes the router consumer and waits for the messages being dispatched to be handled.
+// Dispatchers of handlers removed before the router is closed are awaited as well.
+func (router *Router) Close() {
+	router.deregister()
+	router.closing()
+	router.wg.Wait()
+
+	router.mutex.Lock()
+	dispatchers := router.dispatchers
+	router.dispatchers = make(map[*options.HandlerOptions]*dispatcher)
+	router.mutex.Unlock()
+
+	for _, dispatcher := range dispatchers {
+		dispatcher.close()
+	}
+
+	router.removing.Wait()
+}
+
+// remove removes the given handler from the router.
+// The handler dispatcher is closed in the background since the handler could be removed from within its callback.
+// The removal is tracked under the router mutex, dispatchers removed once the router is closed are no longer
+// present and are closed by the router itself.
+func (router *Router) remove(handler *options.HandlerOptions) {
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	if dispatcher, has := router.dispatchers[handler]; has {
+		delete(router.dispatchers, handler)
+
+		router.removing.Add(1)
+		go func() {
+			defer router.removing.Done()
+			dispatcher.close()
+		}()
+	}
+
+	routes := router.routes[handler.Action]
+	for index, route := range routes {
+		if route == handler {
+			router.routes[handler.Action] = append(routes[:index:index], routes[index+1:]...)
+			break
+		}
+	}
+
+	if len(router.routes[handler.Action]) > 0 {
+		return
+	}
+
+	delete(router.routes, handler.Action)
+
+	for index, prefix := range router.prefixes {
+		if prefix == handler.Action {
+			router.prefixes = append(router.prefixes[:index:index], router.prefixes[index+1:]...)
+			break
+		}
+	}
+}
+
+// match returns the handlers registered for the exact action or the longest prefix matching the given action
+func (router *Router) match(action string) []*options.HandlerOptions {
+	routes, has := router.routes[action]
+	if has {
+		return routes
+	}
+
+	for _, prefix := range router.prefixes {
+		if strings.HasPrefix(action, strings.TrimSuffix(prefix, Wildcard)) {
+			return router.routes[prefix]
+		}
+	}
+
+	return nil
+}
+
+// matches checks if the given action is matched by at least one of the router handlers
+func (router *Router) matches(action string) bool {
+	router.mutex.RLock()
+	defer router.mutex.RUnlock()
+
+	return len(router.match(action)) > 0
+}
+
+// accepts checks if the version of the given message is accepted by the router handlers matching the message action
+func (router *Router) accepts(message *Message) bool {
+	router.mutex.RLock()
+	defer router.mutex.RUnlock()
+
+	for _, route := range router.match(message.Action) {
+		if route.Versions.Contains(message.Version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dispatch passes the given message to the handler registered for the message action and version
+func (router *Router) dispatch(message *Message) {
+	router.mutex.RLock()
+	routes := router.match(message.Action)
+	unhandled := router.unhandled
+	router.mutex.RUnlock()
+
+	if len(routes) == 0 {
+		if unhandled == nil {
+			message.Ack()
+			return
+		}
+
+		unhandled(message, NewWriter(router.group, message))
+		message.Ack()
+		return
+	}
+
+	for _, route := range routes {
+		if !route.Versions.Contains(message.Version) {
+			continue
+		}
+
+		router.mutex.RLock()
+		dispatcher := router.dispatchers[route]
+		router.mutex.RUnlock()
+
+		// NOTE: the route could have been removed after it has been matched
+		if dispatcher == nil || !dispatcher.dispatch(message) {
+			message.Ack()
+		}
+
+		return
+	}
+
+	if router.group.unaccepted(router.sort, message, router) {
+		router.group.fallback(message)
+	}
+
+	message.Ack()
+}
@@ -0,0 +1,336 @@
+package commander
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/testutil"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestRouterDispatch tests if consumed messages are dispatched to the handler of the message action
+func TestRouterDispatch(t *testing.T) {
+	group, _ := NewTestGroup()
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	dispatched := map[string][]string{}
+	for _, action := range []string{"created", "deleted"} {
+		action := action
+		router.HandleFunc(action, func(message *Message, writer Writer) {
+			dispatched[action] = append(dispatched[action], message.Action)
+		})
+	}
+
+	for _, action := range []string{"created", "deleted", "created"} {
+		err := group.ProduceCommand(types.NewMessage(action, 1, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(dispatched["created"]) != 2 || len(dispatched["deleted"]) != 1 {
+		t.Fatalf("unexpected dispatched messages: %v", dispatched)
+	}
+
+	for action, actions := range dispatched {
+		for _, dispatched := range actions {
+			if dispatched != action {
+				t.Fatalf("message %s dispatched to the %s handler", dispatched, action)
+			}
+		}
+	}
+}
+
+// TestRouterPrefix tests if messages are dispatched to the exact action before the longest matching prefix
+func TestRouterPrefix(t *testing.T) {
+	group, _ := NewTestGroup()
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	dispatched := map[string]string{}
+	for _, route := range []string{"*", "account.*", "account.balance.*", "account.created"} {
+		route := route
+		router.HandleFunc(route, func(message *Message, writer Writer) {
+			dispatched[message.Action] = route
+		})
+	}
+
+	expected := map[string]string{
+		"account.created":       "account.created",
+		"account.deleted":       "account.*",
+		"account.balance.added": "account.balance.*",
+		"order.created":         "*",
+	}
+
+	for action := range expected {
+		err := group.ProduceCommand(types.NewMessage(action, 1, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for action, route := range expected {
+		if dispatched[action] != route {
+			t.Fatalf("action %s dispatched to %q, expected %q", action, dispatched[action], route)
+		}
+	}
+}
+
+// TestRouterUnhandled tests if messages without a matching handler are passed to the unhandled handler
+func TestRouterUnhandled(t *testing.T) {
+	group, _ := NewTestGroup()
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	router.HandleFunc("created", func(message *Message, writer Writer) {})
+
+	unhandled := []string{}
+	router.Unhandled(func(message *Message, writer Writer) {
+		unhandled = append(unhandled, message.Action)
+	})
+
+	command := types.NewMessage("deleted", 1, nil, nil)
+
+	for _, message := range []*Message{types.NewMessage("created", 1, nil, nil), command} {
+		err := group.ProduceCommand(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(unhandled) != 1 || unhandled[0] != "deleted" {
+		t.Fatalf("unexpected unhandled actions: %v", unhandled)
+	}
+
+	if command.Finally() != nil {
+		t.Fatal("unhandled message not acknowledged")
+	}
+}
+
+// TestRouterClose tests if closed handlers are no longer dispatched to
+func TestRouterClose(t *testing.T) {
+	group, _ := NewTestGroup()
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	called := 0
+	closing, err := router.HandleFunc("account.*", func(message *Message, writer Writer) {
+		called++
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unhandled := 0
+	router.Unhandled(func(message *Message, writer Writer) {
+		unhandled++
+	})
+
+	closing()
+
+	err = group.ProduceCommand(types.NewMessage("account.created", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if called != 0 || unhandled != 1 {
+		t.Fatal("message dispatched to a closed handler")
+	}
+}
+
+// TestRouterVersions tests if messages are dispatched by version and unsupported versions are passed to the version fallback
+func TestRouterVersions(t *testing.T) {
+	group, recorder := NewTestGroup(
+		WithVersionFallback(VersionNotSupported),
+	)
+
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	versions := []types.Version{}
+	router.HandleContext(
+		WithAction("created"),
+		WithVersionRange(MustParseVersionRange(">=2")),
+		WithCallback(func(message *Message, writer Writer) {
+			versions = append(versions, message.Version)
+		}),
+	)
+
+	unsupported := types.NewMessage("created", 1, nil, nil)
+
+	for _, message := range []*Message{unsupported, types.NewMessage("created", 2, nil, nil)} {
+		err := group.ProduceCommand(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(versions) != 1 || versions[0] != 2 {
+		t.Fatalf("unexpected dispatched versions: %v", versions)
+	}
+
+	failures := recorder.Find(mock.WithParent(unsupported.ID), mock.WithStatus(StatusBadRequest))
+	if len(failures) != 1 {
+		t.Fatal("bad request event not produced")
+	}
+}
+
+// TestRouterConcurrency tests if the concurrency options of a router handler are applied
+func TestRouterConcurrency(t *testing.T) {
+	group := NewGroup(
+		NewTopic("commands", NewStubDialect(), CommandMessage, DefaultMode),
+	)
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &InFlight{}
+	release := make(chan struct{})
+
+	router.HandleContext(
+		WithAction("created"),
+		WithConcurrency(3),
+		WithInFlight(counter),
+		WithCallback(func(message *Message, writer Writer) {
+			<-release
+		}),
+	)
+
+	for i := 0; i < 6; i++ {
+		err := group.ProduceCommand(types.NewMessage("created", 1, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutil.Eventually(t, time.Second, func() bool { return counter.Count() == 3 })
+
+	close(release)
+	router.Close()
+
+	if counter.Count() != 0 {
+		t.Fatalf("router closed while messages are being handled: %d", counter.Count())
+	}
+}
+
+// TestRouterCloseRemoved tests if the router awaits the messages being handled by removed handlers once closed
+func TestRouterCloseRemoved(t *testing.T) {
+	group := NewGroup(
+		NewTopic("commands", NewStubDialect(), CommandMessage, DefaultMode),
+	)
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &InFlight{}
+	release := make(chan struct{})
+
+	closing, err := router.HandleContext(
+		WithAction("created"),
+		WithConcurrency(1),
+		WithInFlight(counter),
+		WithCallback(func(message *Message, writer Writer) {
+			<-release
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = group.ProduceCommand(types.NewMessage("created", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.Eventually(t, time.Second, func() bool { return counter.Count() == 1 })
+
+	closing()
+
+	closed := make(chan struct{})
+	go func() {
+		router.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("router closed while a removed handler is handling a message")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("router not closed once the message is handled")
+	}
+
+	if counter.Count() != 0 {
+		t.Fatalf("router closed while messages are being handled: %d", counter.Count())
+	}
+}
+
+// TestRouterNoAction tests if a error is returned when registering a handler without a action
+func TestRouterNoAction(t *testing.T) {
+	group, _ := NewTestGroup()
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	_, err = router.HandleFunc("", func(message *Message, writer Writer) {})
+	if err != ErrNoAction {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// BenchmarkRouterDispatch benchmarks the dispatching of messages over a router with many registered actions
+func BenchmarkRouterDispatch(b *testing.B) {
+	group, _ := NewTestGroup()
+
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer router.Close()
+
+	for i := 0; i < 40; i++ {
+		router.HandleFunc(string(rune('a'+i)), func(message *Message, writer Writer) {})
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		group.ProduceCommand(types.NewMessage("a", 1, nil, nil))
+	}
+}
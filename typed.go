@@ -0,0 +1,66 @@
+package commander
+
+import (
+	"fmt"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// UnexpectedSchemaError is returned when a decoded message schema does not match the payload type of a typed handler
+type UnexpectedSchemaError struct {
+	Schema interface{}
+}
+
+// Error returns the type of the unexpected schema
+func (err *UnexpectedSchemaError) Error() string {
+	return fmt.Sprintf("unexpected message schema type %T", err.Schema)
+}
+
+// TypedHandlerFunc message handle receiving the decoded message payload
+type TypedHandlerFunc[T any] func(message *Message, writer Writer, payload T)
+
+// HandleTyped awaits messages from the given MessageType and action. The data of received messages is decoded
+// with the group codec into the payload type of the given handler before the handler is called.
+// If the message data could not be decoded, or if the codec could not decode the data into the payload type
+// (ex: no codec is configured or the codec decodes into its own types), is a StatusBadRequest error event produced with the message action,
+// the message is acknowledged without calling the handler. Additional handler options (ex: WithVersion) could be given.
+func HandleTyped[T any](group *Group, sort types.MessageType, action string, handler TypedHandlerFunc[T], definitions ...options.HandlerOption) (Close, error) {
+	definitions = append([]options.HandlerOption{
+		WithAction(action),
+		WithMessageType(sort),
+		WithMessageSchema(func() interface{} {
+			return new(T)
+		}),
+		WithCallback(func(message *Message, writer Writer) {
+			payload, err := Payload[T](message)
+			if err != nil {
+				writer.Error(message.Action, StatusBadRequest, err)
+				return
+			}
+
+			handler(message, writer, payload)
+		}),
+	}, definitions...)
+
+	return group.HandleContext(definitions...)
+}
+
+// Payload returns the decoded schema of the given message as the given payload type.
+// The schema decoding error is returned if the message schema could not be decoded,
+// a UnexpectedSchemaError is returned if the decoded schema is not of the given payload type.
+func Payload[T any](message *Message) (payload T, err error) {
+	err = message.SchemaError()
+	if err != nil {
+		return payload, err
+	}
+
+	switch schema := message.Schema().(type) {
+	case *T:
+		return *schema, nil
+	case T:
+		return schema, nil
+	default:
+		return payload, &UnexpectedSchemaError{schema}
+	}
+}
@@ -0,0 +1,147 @@
+package commander
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/codecs/cbor"
+	"github.com/jeroenrinzema/commander/codecs/msgpack"
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+type account struct {
+	Name    string `json:"name" msgpack:"name" cbor:"name"`
+	Balance int    `json:"balance" msgpack:"balance" cbor:"balance"`
+}
+
+// nativeCodec decodes all messages into a native map regardless of the requested schema (ex: avro)
+type nativeCodec struct {
+	options.JSONCodec
+}
+
+func (codec *nativeCodec) Apply(options *options.GroupOptions) {
+	options.Codec = codec
+}
+
+func (codec *nativeCodec) UnmarshalMessage(message *types.Message) (interface{}, error) {
+	return map[string]interface{}{"name": "john"}, nil
+}
+
+// TestHandleTyped tests if typed handlers receive the decoded message payload
+func TestHandleTyped(t *testing.T) {
+	for name, codec := range map[string]options.GroupOption{"json": WithJSONCodec(), "msgpack": msgpack.WithMsgPackCodec(), "cbor": cbor.WithCBORCodec()} {
+		t.Run(name, func(t *testing.T) {
+			group, _ := NewTestGroup(codec)
+			payloads := make(chan account, 1)
+
+			_, err := HandleTyped(group, CommandMessage, "create", func(message *Message, writer Writer, payload account) {
+				payloads <- payload
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := group.Codec.Marshal(account{Name: "john", Balance: 42})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = group.ProduceCommand(types.NewMessage("create", 1, nil, data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case payload := <-payloads:
+				if payload.Name != "john" || payload.Balance != 42 {
+					t.Fatalf("unexpected payload: %+v", payload)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("typed handler not called")
+			}
+		})
+	}
+}
+
+// TestHandleTypedBadRequest tests if a bad request error event is produced when the message data could not be decoded
+func TestHandleTypedBadRequest(t *testing.T) {
+	group, recorder := NewTestGroup(WithJSONCodec())
+	called := false
+
+	HandleTyped(group, CommandMessage, "create", func(message *Message, writer Writer, payload account) {
+		called = true
+	})
+
+	command := types.NewMessage("create", 1, nil, []byte(`{"balance":"invalid"}`))
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if command.Finally() != nil {
+		t.Fatal("command not acknowledged")
+	}
+
+	if called {
+		t.Fatal("typed handler called with invalid data")
+	}
+
+	events := recorder.Find(mock.WithParent(command.ID), mock.WithStatus(StatusBadRequest))
+	if len(events) != 1 {
+		t.Fatalf("unexpected amount of bad request events: %d", len(events))
+	}
+
+	if !events[0].EOS || events[0].Topic.Name() != "events" {
+		t.Fatal("unexpected bad request event")
+	}
+}
+
+// TestPayloadUnexpectedSchema tests if a error is returned when the message schema does not match the payload type
+func TestPayloadUnexpectedSchema(t *testing.T) {
+	message := types.NewMessage("create", 1, nil, nil)
+	message.NewSchema(map[string]interface{}{})
+
+	_, err := Payload[account](message)
+	if _, ok := err.(*UnexpectedSchemaError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestHandleTypedSchemaError tests if typed handlers are not called when the group codec could not decode the payload type
+func TestHandleTypedSchemaError(t *testing.T) {
+	tests := map[string][]options.GroupOption{
+		"ignore": nil,
+		"native": {&nativeCodec{}},
+	}
+
+	for name, codec := range tests {
+		t.Run(name, func(t *testing.T) {
+			group, recorder := NewTestGroup(codec...)
+			called := false
+
+			HandleTyped(group, CommandMessage, "create", func(message *Message, writer Writer, payload account) {
+				called = true
+			})
+
+			command := types.NewMessage("create", 1, nil, []byte(`{"name":"john"}`))
+
+			err := group.ProduceCommand(command)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if called {
+				t.Fatal("typed handler called with a undecoded payload")
+			}
+
+			events := recorder.Find(mock.WithParent(command.ID), mock.WithStatus(StatusBadRequest))
+			if len(events) != 1 {
+				t.Fatalf("unexpected amount of bad request events: %d", len(events))
+			}
+		})
+	}
+}
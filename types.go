@@ -26,6 +26,13 @@ const (
 	CommandMessage = types.CommandMessage
 )
 
+// Available produce policies
+const (
+	ProduceAll        = options.ProduceAll
+	ProduceBestEffort = options.ProduceBestEffort
+	ProducePrimary    = options.ProducePrimary
+)
+
 // Dialect extention of the Dialect type
 type Dialect = types.Dialect
 
@@ -47,6 +54,21 @@ var NewTopic = options.NewTopic
 // Topic contains information of a kafka topic
 type Topic = types.Topic
 
+// Upcaster transforms a message from its current version into the next version
+type Upcaster = types.Upcaster
+
+// VersionRange represents a inclusive range of message versions
+type VersionRange = types.VersionRange
+
+// ParseVersionRange types.ParseVersionRange alias
+var ParseVersionRange = types.ParseVersionRange
+
+// MustParseVersionRange types.MustParseVersionRange alias
+var MustParseVersionRange = types.MustParseVersionRange
+
+// InFlight keeps track of the amount of messages that are currently being handled
+type InFlight = types.InFlight
+
 // NewMessage types.NewMessage alias
 var NewMessage = types.NewMessage
 
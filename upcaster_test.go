@@ -0,0 +1,161 @@
+package commander
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+type accountV3 struct {
+	FullName string `json:"full_name"`
+	Balance  int    `json:"balance"`
+	Currency string `json:"currency"`
+}
+
+// upcastV1 renames the v1 "name" field to the v2 "full_name" field
+func upcastV1(message *Message) error {
+	payload := map[string]interface{}{}
+	err := json.Unmarshal(message.Data, &payload)
+	if err != nil {
+		return err
+	}
+
+	payload["full_name"] = payload["name"]
+	delete(payload, "name")
+
+	message.Data, err = json.Marshal(payload)
+	return err
+}
+
+// upcastV2 introduces the v3 "currency" field
+func upcastV2(message *Message) error {
+	payload := map[string]interface{}{}
+	err := json.Unmarshal(message.Data, &payload)
+	if err != nil {
+		return err
+	}
+
+	payload["currency"] = "EUR"
+
+	message.Data, err = json.Marshal(payload)
+	return err
+}
+
+// TestUpcastHandler tests if consumed messages are upcasted step by step to the version the handler expects
+func TestUpcastHandler(t *testing.T) {
+	group, _ := NewTestGroup(
+		WithJSONCodec(),
+		WithUpcaster("created", 1, upcastV1),
+		WithUpcaster("created", 2, upcastV2),
+	)
+
+	type result struct {
+		version types.Version
+		payload accountV3
+	}
+
+	results := make(chan result, 2)
+	HandleTyped(group, CommandMessage, "created", func(message *Message, writer Writer, payload accountV3) {
+		results <- result{message.Version, payload}
+	}, WithVersion(3))
+
+	original := []byte(`{"name":"john","balance":42}`)
+	command := types.NewMessage("created", 1, nil, original)
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case result := <-results:
+		if result.version != 3 {
+			t.Fatalf("unexpected message version: %d", result.version)
+		}
+
+		if result.payload.FullName != "john" || result.payload.Balance != 42 || result.payload.Currency != "EUR" {
+			t.Fatalf("unexpected upcasted payload: %+v", result.payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+
+	if command.Version != 1 || string(command.Data) != string(original) {
+		t.Fatal("consumed message modified")
+	}
+
+	if command.Finally() != nil {
+		t.Fatal("consumed message not acknowledged")
+	}
+
+	current := types.NewMessage("created", 3, nil, []byte(`{"full_name":"jane","balance":1,"currency":"USD"}`))
+
+	err = group.ProduceCommand(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := <-results; result.payload.FullName != "jane" || result.payload.Currency != "USD" {
+		t.Fatalf("unexpected payload: %+v", result.payload)
+	}
+}
+
+// TestUpcastMissingUpcaster tests if a bad request event is produced when a upcaster is missing
+func TestUpcastMissingUpcaster(t *testing.T) {
+	group, recorder := NewTestGroup(
+		WithJSONCodec(),
+		WithUpcaster("created", 2, upcastV2),
+	)
+
+	called := false
+	HandleTyped(group, CommandMessage, "created", func(message *Message, writer Writer, payload accountV3) {
+		called = true
+	}, WithVersion(3))
+
+	command := types.NewMessage("created", 1, nil, []byte(`{"name":"john","balance":42}`))
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if called {
+		t.Fatal("handler called while the message could not be upcasted")
+	}
+
+	failures := recorder.Find(mock.WithParent(command.ID), mock.WithStatus(StatusBadRequest))
+	if len(failures) != 1 {
+		t.Fatal("bad request event not produced")
+	}
+}
+
+// TestUpcastNack tests if the consumed message is negative acknowledged when the upcasted message got negative acknowledged
+func TestUpcastNack(t *testing.T) {
+	group, _ := NewTestGroup(
+		WithJSONCodec(),
+		WithUpcaster("created", 1, upcastV1),
+	)
+
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersion(2),
+		WithCallback(func(message *Message, writer Writer) {
+			message.Nack()
+		}),
+	)
+
+	command := types.NewMessage("created", 1, nil, []byte(`{"name":"john"}`))
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if command.Finally() != types.ErrNegativeAcknowledgement {
+		t.Fatal("consumed message not negative acknowledged")
+	}
+}
@@ -0,0 +1,133 @@
+package commander
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jeroenrinzema/commander/internal/options"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// VersionNotSupportedError is returned when no handler accepts the version of a consumed message
+type VersionNotSupportedError struct {
+	Action  string
+	Version types.Version
+}
+
+// Error returns the action and version which are not supported
+func (err *VersionNotSupportedError) Error() string {
+	return fmt.Sprintf("version %s of action %s is not supported", err.Version, err.Action)
+}
+
+// VersionNotSupported is a version fallback producing a StatusBadRequest error event with the message action.
+// Consumed error events are ignored to avoid answering a unsupported error event with a other error event.
+func VersionNotSupported(message *Message, writer Writer) {
+	if message.Status != types.NullStatusCode && message.Status != StatusOK {
+		return
+	}
+
+	writer.Error(message.Action, StatusBadRequest, &VersionNotSupportedError{message.Action, message.Version})
+}
+
+// DeadLetter returns a version fallback publishing a copy of the consumed message to the given (dead letter) topic.
+// The consumed message is negative acknowledged if the copy could not be published.
+func DeadLetter(topic Topic) HandlerFunc {
+	return func(message *Message, writer Writer) {
+		letter := message.Copy()
+		letter.Topic = topic
+
+		retry := Retry{
+			Amount: options.DefaultRetries,
+		}
+
+		err := retry.Attempt(func() error {
+			return topic.Dialect().Producer().Publish(letter)
+		})
+
+		if err != nil {
+			message.Nack()
+		}
+	}
+}
+
+// registration represents a consumer (ex: a handler or router) registered for messages of a message type.
+// The matches method reports if a message action is handled by the consumer, the accepts method reports
+// if the consumer accepts the version of a message whose action is matched.
+type registration struct {
+	owner   interface{}
+	matches func(action string) bool
+	accepts func(message *Message) bool
+}
+
+// versions keeps track of the consumers registered per message type, in order of registration.
+// The registrations are shared between the handlers and routers of a group to decide if a consumed message
+// is not accepted by any consumer and should be passed to the group version fallback.
+type versions struct {
+	registrations map[types.MessageType][]*registration
+	mutex         sync.RWMutex
+}
+
+// register registers the given consumer registration for the given message type.
+// The returned function should be called to deregister the consumer.
+func (group *Group) register(sort types.MessageType, consumer *registration) func() {
+	group.versions.mutex.Lock()
+	defer group.versions.mutex.Unlock()
+
+	group.versions.registrations[sort] = append(group.versions.registrations[sort], consumer)
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			group.versions.mutex.Lock()
+			defer group.versions.mutex.Unlock()
+
+			registrations := group.versions.registrations[sort]
+			for index, registered := range registrations {
+				if registered == consumer {
+					group.versions.registrations[sort] = append(registrations[:index:index], registrations[index+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// unaccepted checks if the given message, which is not handled by the given owner, should be passed to the version fallback.
+// True is returned if the message action is matched by at least one registered consumer, none of the consumers accepts the
+// message version and the given owner is the first registered consumer matching the message action. Every consumer receives
+// the consumed message, the message is passed only once to the version fallback by checking the owner.
+// False is returned if no version fallback is configured.
+func (group *Group) unaccepted(sort types.MessageType, message *Message, owner interface{}) bool {
+	if group.VersionFallback == nil {
+		return false
+	}
+
+	group.versions.mutex.RLock()
+	defer group.versions.mutex.RUnlock()
+
+	var first *registration
+
+	for _, consumer := range group.versions.registrations[sort] {
+		if !consumer.matches(message.Action) {
+			continue
+		}
+
+		if consumer.accepts(message) {
+			return false
+		}
+
+		if first == nil {
+			first = consumer
+		}
+	}
+
+	return first != nil && first.owner == owner
+}
+
+// fallback passes the given message to the group version fallback
+func (group *Group) fallback(message *Message) {
+	group.logger.Debugf("version %s of action %s not accepted by any handler", message.Version, message.Action)
+
+	writer := NewWriter(group, message)
+	group.VersionFallback(message, writer)
+}
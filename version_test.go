@@ -0,0 +1,272 @@
+package commander
+
+import (
+	"testing"
+
+	"github.com/jeroenrinzema/commander/dialects/mock"
+	"github.com/jeroenrinzema/commander/internal/types"
+)
+
+// TestParseVersionRange tests if version range expressions are parsed
+func TestParseVersionRange(t *testing.T) {
+	tests := map[string]VersionRange{
+		"":       {},
+		"*":      {},
+		"2":      {Min: 2, Max: 2},
+		"=2":     {Min: 2, Max: 2},
+		">=2":    {Min: 2},
+		">2":     {Min: 3},
+		"<=2":    {Max: 2},
+		"<3":     {Max: 2},
+		"1-3":    {Min: 1, Max: 3},
+		">=2,<4": {Min: 2, Max: 3},
+	}
+
+	for expr, expected := range tests {
+		result, err := ParseVersionRange(expr)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", expr, err)
+		}
+
+		if result != expected {
+			t.Fatalf("unexpected version range for %q: %+v, expected %+v", expr, result, expected)
+		}
+	}
+
+	invalid := []string{"a", ">=0", "<1", ">127", "3-1", "=-1", ">=2,x"}
+	for _, expr := range invalid {
+		_, err := ParseVersionRange(expr)
+		if err == nil {
+			t.Fatalf("expected a error for %q", expr)
+		}
+	}
+}
+
+// TestVersionRangeContains tests if versions inside of a range are matched
+func TestVersionRangeContains(t *testing.T) {
+	r := MustParseVersionRange("2-3")
+
+	if r.Contains(1) || !r.Contains(2) || !r.Contains(3) || r.Contains(4) {
+		t.Fatalf("unexpected version range matches for %s", r)
+	}
+
+	if !(VersionRange{}).Contains(1) {
+		t.Fatal("zero version range does not contain all versions")
+	}
+}
+
+// TestHandleVersionRange tests if consumed messages are only passed to the handlers accepting the message version
+func TestHandleVersionRange(t *testing.T) {
+	group, _ := NewTestGroup()
+
+	legacy := []types.Version{}
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange("1")),
+		WithCallback(func(message *Message, writer Writer) {
+			legacy = append(legacy, message.Version)
+		}),
+	)
+
+	current := []types.Version{}
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange(">=2")),
+		WithCallback(func(message *Message, writer Writer) {
+			current = append(current, message.Version)
+		}),
+	)
+
+	for _, version := range []int8{1, 2, 3} {
+		err := group.ProduceCommand(types.NewMessage("created", version, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(legacy) != 1 || legacy[0] != 1 {
+		t.Fatalf("unexpected versions consumed by the legacy handler: %v", legacy)
+	}
+
+	if len(current) != 2 || current[0] != 2 || current[1] != 3 {
+		t.Fatalf("unexpected versions consumed by the current handler: %v", current)
+	}
+}
+
+// TestVersionFallbackHandler tests if messages with a unsupported version are passed once to the fallback handler
+func TestVersionFallbackHandler(t *testing.T) {
+	fallbacks := []*Message{}
+	group, _ := NewTestGroup(
+		WithVersionFallback(func(message *Message, writer Writer) {
+			fallbacks = append(fallbacks, message)
+		}),
+	)
+
+	for _, r := range []string{"2", "3"} {
+		group.HandleContext(
+			WithAction("created"),
+			WithMessageType(CommandMessage),
+			WithVersionRange(MustParseVersionRange(r)),
+			WithCallback(func(message *Message, writer Writer) {}),
+		)
+	}
+
+	unsupported := types.NewMessage("created", 1, nil, nil)
+	messages := []*Message{
+		unsupported,
+		types.NewMessage("created", 2, nil, nil),
+		types.NewMessage("created", 3, nil, nil),
+		types.NewMessage("deleted", 1, nil, nil),
+	}
+
+	for _, message := range messages {
+		err := group.ProduceCommand(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(fallbacks) != 1 || fallbacks[0].ID != unsupported.ID {
+		t.Fatalf("unexpected messages passed to the version fallback: %d", len(fallbacks))
+	}
+}
+
+// TestVersionFallbackRouter tests if messages are only passed to the version fallback when not accepted by any handler or router
+func TestVersionFallbackRouter(t *testing.T) {
+	fallbacks := []types.Version{}
+	group, _ := NewTestGroup(
+		WithVersionFallback(func(message *Message, writer Writer) {
+			fallbacks = append(fallbacks, message.Version)
+		}),
+	)
+
+	router, err := group.NewRouter(CommandMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer router.Close()
+
+	router.HandleContext(
+		WithAction("created"),
+		WithVersionRange(MustParseVersionRange("2")),
+		WithCallback(func(message *Message, writer Writer) {}),
+	)
+
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange("1")),
+		WithCallback(func(message *Message, writer Writer) {}),
+	)
+
+	for _, version := range []int8{1, 2, 3} {
+		err := group.ProduceCommand(types.NewMessage("created", version, nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(fallbacks) != 1 || fallbacks[0] != 3 {
+		t.Fatalf("unexpected versions passed to the version fallback: %v", fallbacks)
+	}
+}
+
+// TestVersionFallbackClose tests if the version fallback is no longer called once all handlers are closed
+func TestVersionFallbackClose(t *testing.T) {
+	called := 0
+	group, _ := NewTestGroup(
+		WithVersionFallback(func(message *Message, writer Writer) {
+			called++
+		}),
+	)
+
+	closing, err := group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange("2")),
+		WithCallback(func(message *Message, writer Writer) {}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closing()
+
+	err = group.ProduceCommand(types.NewMessage("created", 1, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if called != 0 {
+		t.Fatal("version fallback called after all handlers are closed")
+	}
+}
+
+// TestVersionNotSupported tests if a bad request error event is produced for messages with a unsupported version
+func TestVersionNotSupported(t *testing.T) {
+	group, recorder := NewTestGroup(
+		WithVersionFallback(VersionNotSupported),
+	)
+
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange(">=2")),
+		WithCallback(func(message *Message, writer Writer) {}),
+	)
+
+	command := types.NewMessage("created", 1, nil, nil)
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := recorder.Find(mock.WithParent(command.ID), mock.WithStatus(StatusBadRequest))
+	if len(failures) != 1 {
+		t.Fatal("bad request event not produced")
+	}
+
+	if command.Finally() != nil {
+		t.Fatal("consumed message not acknowledged")
+	}
+}
+
+// TestVersionFallbackDeadLetter tests if messages with a unsupported version are published to the dead letter topic
+func TestVersionFallbackDeadLetter(t *testing.T) {
+	recorder := mock.NewRecorder()
+	dialect := mock.NewDialect(mock.WithRecorder(recorder), mock.WithSynchronousDelivery())
+	letters := types.NewTopic("dead-letters", dialect, CommandMessage, ProduceMode)
+
+	group := NewGroup(
+		NewTopic("commands", dialect, CommandMessage, DefaultMode),
+		WithVersionFallback(DeadLetter(letters)),
+	)
+
+	group.HandleContext(
+		WithAction("created"),
+		WithMessageType(CommandMessage),
+		WithVersionRange(MustParseVersionRange(">=2")),
+		WithCallback(func(message *Message, writer Writer) {}),
+	)
+
+	command := types.NewMessage("created", 1, nil, []byte("payload"))
+
+	err := group.ProduceCommand(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dead := recorder.Topic("dead-letters")
+	if len(dead) != 1 {
+		t.Fatalf("unexpected amount of dead letters: %d", len(dead))
+	}
+
+	if dead[0].ID != command.ID || dead[0].Version != 1 || string(dead[0].Data) != "payload" {
+		t.Fatal("unexpected dead letter")
+	}
+}
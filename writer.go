@@ -109,3 +109,68 @@ func (writer *writer) CommandStream(action string, version int8, key []byte, dat
 	err := writer.group.ProduceCommand(message)
 	return message, err
 }
+
+// NewValueMessage constructs a new message or a child of the parent with the given value encoded as message data.
+// The value is encoded with the codec of the parent content type if a codec is registered for it inside the group,
+// otherwise is the group default codec used.
+func (writer *writer) NewValueMessage(action string, version int8, key []byte, value interface{}) (*Message, error) {
+	message := writer.NewMessage(action, version, key, nil)
+
+	if writer.parent != nil {
+		contentType, has := metadata.ContentTypeFromContext(writer.parent.Ctx())
+		if _, registered := writer.group.Codecs[contentType]; has && registered {
+			message.NewCtx(metadata.NewContentTypeContext(message.Ctx(), contentType))
+		}
+	}
+
+	err := writer.group.Encode(message, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (writer *writer) EventValue(action string, version int8, key []byte, value interface{}) (*Message, error) {
+	message, err := writer.NewValueMessage(action, version, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	message.EOS = true
+
+	err = writer.group.ProduceEvent(message)
+	return message, err
+}
+
+func (writer *writer) EventStreamValue(action string, version int8, key []byte, value interface{}) (*Message, error) {
+	message, err := writer.NewValueMessage(action, version, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.group.ProduceEvent(message)
+	return message, err
+}
+
+func (writer *writer) CommandValue(action string, version int8, key []byte, value interface{}) (*Message, error) {
+	message, err := writer.NewValueMessage(action, version, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	message.EOS = true
+
+	err = writer.group.ProduceCommand(message)
+	return message, err
+}
+
+func (writer *writer) CommandStreamValue(action string, version int8, key []byte, value interface{}) (*Message, error) {
+	message, err := writer.NewValueMessage(action, version, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.group.ProduceCommand(message)
+	return message, err
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jeroenrinzema/commander/codecs/msgpack"
+	"github.com/jeroenrinzema/commander/dialects/mock"
 	"github.com/jeroenrinzema/commander/internal/metadata"
 )
 
@@ -278,3 +280,80 @@ func TestWriterProduceErrorEventStream(t *testing.T) {
 		t.Error("the events handle was not called within the deadline")
 	}
 }
+
+// TestWriterEventValue tests if able to write a event value encoded with the group codec
+func TestWriterEventValue(t *testing.T) {
+	group, recorder := NewTestGroup(WithJSONCodec())
+	parent := NewMessage("testing", 1, nil, nil)
+	writer := NewWriter(group, parent)
+
+	message, err := writer.EventValue("created", 1, nil, account{Name: "john", Balance: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(message.Data) != `{"name":"john","balance":42}` {
+		t.Fatalf("unexpected message data: %s", message.Data)
+	}
+
+	if !message.EOS {
+		t.Fatal("event value not marked as EOS")
+	}
+
+	contentType, _ := metadata.ContentTypeFromContext(message.Ctx())
+	if contentType != "application/json" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+
+	if len(recorder.Find(mock.WithTopic("events"), mock.WithParent(parent.ID))) != 1 {
+		t.Fatal("event value not produced")
+	}
+}
+
+// TestWriterCommandStreamValue tests if able to write a command stream value encoded with the group codec
+func TestWriterCommandStreamValue(t *testing.T) {
+	group, recorder := NewTestGroup(msgpack.WithMsgPackCodec())
+	writer := NewWriter(group, nil)
+
+	message, err := writer.CommandStreamValue("create", 1, nil, account{Name: "john", Balance: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.EOS {
+		t.Fatal("command stream value marked as EOS")
+	}
+
+	decoded := account{}
+	err = group.Codec.Unmarshal(message.Data, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Name != "john" || decoded.Balance != 42 {
+		t.Fatalf("unexpected decoded value: %+v", decoded)
+	}
+
+	if len(recorder.Topic("commands")) != 1 {
+		t.Fatal("command value not produced")
+	}
+}
+
+// TestWriterValueMarshalError tests if a marshal error is returned without producing a message
+func TestWriterValueMarshalError(t *testing.T) {
+	group, recorder := NewTestGroup(WithJSONCodec())
+	writer := NewWriter(group, nil)
+
+	message, err := writer.CommandValue("create", 1, nil, make(chan int))
+	if err == nil {
+		t.Fatal("expected a marshal error")
+	}
+
+	if message != nil {
+		t.Fatal("unexpected message returned")
+	}
+
+	if len(recorder.Messages()) != 0 {
+		t.Fatal("message produced while the value could not be encoded")
+	}
+}